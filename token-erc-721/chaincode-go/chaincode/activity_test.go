@@ -0,0 +1,47 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountActivitySummary(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	stub.MockTransactionStart("tx2")
+	_, err := tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	stub.MockTransactionStart("tx3")
+	_, err = tokenContract.TransferSale(recipientCtx, recipient, minter, "101", 250)
+	require.NoError(t, err)
+	stub.MockTransactionStart("tx4")
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "102")
+	require.NoError(t, err)
+	stub.MockTransactionStart("tx5")
+	_, err = tokenContract.Burn(minterCtx, "103")
+	require.NoError(t, err)
+	stub.MockTransactionStart("tx6")
+	_, err = tokenContract.MintEdition(minterCtx, "104", "https://example.com/nft/104", 5)
+	require.NoError(t, err)
+	stub.MockTransactionStart("tx7")
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "104")
+	require.NoError(t, err)
+
+	summary, err := tokenContract.GetAccountActivitySummary(minterCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AccountSummary{Account: minter, Balance: 5, Minted: 8, Received: 1, Sent: 3, Burned: 1}, summary)
+
+	summary, err = tokenContract.GetAccountActivitySummary(minterCtx, recipient)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AccountSummary{Account: recipient, Balance: 2, Received: 3, Sent: 1}, summary)
+
+	summary, err = tokenContract.GetAccountActivitySummary(minterCtx, operator)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AccountSummary{Account: operator}, summary)
+}
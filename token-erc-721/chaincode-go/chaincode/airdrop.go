@@ -0,0 +1,131 @@
+package chaincode
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+)
+
+// Airdrop transfers tokenIDs[i] from the calling admin to recipients[i], in one transaction
+// The caller must own every token. A recipient may be listed several times to receive several tokens,
+// the balance cap is then checked against all of them together.
+// Every token is checked before any is moved, and nothing is committed if any check fails.
+// This function triggers a single Airdrop event listing the recipients and tokens
+func (s *SmartContract) Airdrop(ctx contractapi.TransactionContextInterface, recipients []string, tokenIDs []string) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(recipients) != len(tokenIDs) {
+		return fmt.Errorf("got %d recipients but %d token IDs", len(recipients), len(tokenIDs))
+	}
+	err = checkBatchSize(ctx, len(tokenIDs))
+	if err != nil {
+		return err
+	}
+
+	incoming := make(map[string]int, len(recipients))
+	seenTokens := make(map[string]bool, len(tokenIDs))
+	nfts := make([]*Nft, 0, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		recipient := recipients[i]
+		if seenTokens[tokenID] {
+			return fmt.Errorf("token %s appears more than once", tokenID)
+		}
+		seenTokens[tokenID] = true
+
+		nft, err := readNFT(ctx, tokenID)
+		if err != nil {
+			return err
+		}
+		if nft.isEdition() {
+			return fmt.Errorf("token %s is an edition of %d copies and cannot be airdropped", tokenID, nft.Supply)
+		}
+		if nft.Owner != sender {
+			return fmt.Errorf("non-fungible token %s is not owned by %s", tokenID, sender)
+		}
+
+		// Offered and restricted tokens can only change hands through AcceptTransfer
+		offer, err := readOffer(ctx, tokenID)
+		if err != nil {
+			return err
+		}
+		if offer != nil {
+			return fmt.Errorf("token %s has a pending transfer offer to %s", tokenID, offer.To)
+		}
+		restrictedTo, err := readRestriction(ctx, tokenID)
+		if err != nil {
+			return err
+		}
+		if restrictedTo != "" {
+			return fmt.Errorf("token %s is restricted to %s, transfer it with OfferTransfer", tokenID, restrictedTo)
+		}
+
+		err = checkMove(ctx, nft, sender, recipient)
+		if err != nil {
+			return err
+		}
+		nfts = append(nfts, nft)
+		incoming[recipient]++
+	}
+
+	// The cap rule checked each token against the balance before the airdrop, since a transaction does not
+	// read its own writes. Check the total of a recipient listed several times as well.
+	capEnabled, err := isTransferRuleEnabled(ctx, "cap")
+	if err != nil {
+		return err
+	}
+	if capEnabled {
+		for _, recipient := range recipients {
+			if incoming[recipient] < 2 {
+				continue
+			}
+			err = checkBalanceCap(ctx, recipient, incoming[recipient])
+			if err != nil {
+				return fmt.Errorf("transfer rejected by rule cap: %v", err)
+			}
+		}
+	}
+
+	for i, nft := range nfts {
+		err = applyMove(ctx, nft, sender, recipients[i])
+		if err != nil {
+			return err
+		}
+		err = appendOwnerChange(ctx, nft.TokenID, OwnerChange{From: sender, To: recipients[i]})
+		if err != nil {
+			return err
+		}
+		err = recordActivity(ctx, nft.TokenID, sender, recipients[i], 1)
+		if err != nil {
+			return err
+		}
+		err = invokeTransferCallback(ctx, sender, recipients[i], nft.TokenID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = touchTokens(ctx, tokenIDs)
+	if err != nil {
+		return err
+	}
+
+	err = emitEvent(ctx, "Airdrop", events.AirdropEvent{From: sender, Recipients: recipients, TokenIDs: tokenIDs})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%d tokens of %s airdropped", len(tokenIDs), sender)
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAirdrop(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103", "104")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	recipients := []string{recipient, operator, "collector"}
+	tokenIDs := []string{"101", "102", "103"}
+
+	err := tokenContract.Airdrop(minterCtx, recipients, tokenIDs[:2])
+	require.EqualError(t, err, "got 3 recipients but 2 token IDs")
+	err = tokenContract.Airdrop(prepMocks(stub, org2MSP, recipient), recipients, tokenIDs)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	// A token the caller does not own leaves every token in place
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "104")
+	require.NoError(t, err)
+	err = tokenContract.Airdrop(minterCtx, []string{operator, "collector"}, []string{"101", "104"})
+	require.EqualError(t, err, "non-fungible token 104 is not owned by "+minter)
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, minter, owner)
+
+	drainEvents(stub)
+	err = tokenContract.Airdrop(minterCtx, recipients, tokenIDs)
+	require.NoError(t, err)
+
+	for i, tokenID := range tokenIDs {
+		owner, err := tokenContract.OwnerOf(minterCtx, tokenID)
+		require.NoError(t, err)
+		require.Equal(t, recipients[i], owner)
+	}
+	balance, err := tokenContract.BalanceOf(minterCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 0, balance)
+	balance, err = tokenContract.BalanceOf(minterCtx, "collector")
+	require.NoError(t, err)
+	require.Equal(t, 1, balance)
+
+	changes, err := tokenContract.GetOwnerChangeEvents(minterCtx, "103")
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+
+	emitted := drainEvents(stub)
+	require.Len(t, emitted, 1)
+	require.Equal(t, "Airdrop", emitted[0].EventName)
+	event, err := events.DecodeAirdropEvent(emitted[0].Payload)
+	require.NoError(t, err)
+	require.Equal(t, events.AirdropEvent{From: minter, Recipients: []string{recipient, operator, "collector"}, TokenIDs: []string{"101", "102", "103"}}, event)
+}
+
+func TestAirdropRepeatedRecipient(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	require.NoError(t, tokenContract.EnableTransferRule(minterCtx, "cap", true))
+	require.NoError(t, tokenContract.SetBalanceCap(minterCtx, 2))
+
+	// The balance cap counts every token a recipient receives in the airdrop
+	err := tokenContract.Airdrop(minterCtx, []string{recipient, recipient, recipient}, []string{"101", "102", "103"})
+	require.EqualError(t, err, "transfer rejected by rule cap: recipient recipient would exceed the balance cap of 2")
+
+	err = tokenContract.Airdrop(minterCtx, []string{recipient, recipient, operator}, []string{"101", "102", "103"})
+	require.NoError(t, err)
+	balance, err := tokenContract.BalanceOf(minterCtx, recipient)
+	require.NoError(t, err)
+	require.Equal(t, 2, balance)
+	balance, err = tokenContract.BalanceOf(minterCtx, operator)
+	require.NoError(t, err)
+	require.Equal(t, 1, balance)
+}
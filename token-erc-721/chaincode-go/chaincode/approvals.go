@@ -0,0 +1,347 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+)
+
+// Define objectType names for prefix
+const approvalLogPrefix = "approvalLog"
+const approvalLogSequencePrefix = "approvalLogSequence"
+
+// ApprovalForAllChange is one grant or revoke of an operator, as recorded by SetApprovalForAll
+type ApprovalForAllChange struct {
+	Owner     string `json:"owner"`
+	Operator  string `json:"operator"`
+	Approved  bool   `json:"approved"`
+	Timestamp int64  `json:"timestamp"`
+	TxID      string `json:"txId"`
+}
+
+// ApprovalForAllChangesPage is one page of the result of GetApprovalForAllEvents
+// Bookmark is empty when there are no further pages
+type ApprovalForAllChangesPage struct {
+	Records  []ApprovalForAllChange `json:"records"`
+	Bookmark string                 `json:"bookmark"`
+}
+
+// ApprovalStatus combines the single-token approval of a non-fungible token with the operators of its owner
+type ApprovalStatus struct {
+	TokenID   string   `json:"tokenId"`
+	Owner     string   `json:"owner"`
+	Approved  string   `json:"approved"`
+	Operators []string `json:"operators"`
+}
+
+// GetApprovalsExpiringBefore returns the tokens of an owner whose single-token approval lapses before unixTime
+// Approvals for all never expire and approvals that have already lapsed are not reported.
+func (s *SmartContract) GetApprovalsExpiringBefore(ctx contractapi.TransactionContextInterface, owner string, unixTime int64) ([]*Nft, error) {
+	tokenIDs, err := ownedTokenIDs(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	nfts := []*Nft{}
+	for _, tokenID := range tokenIDs {
+		nft, err := readNFT(ctx, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		if nft.ApprovedUntil == 0 || nft.ApprovedUntil >= unixTime {
+			continue
+		}
+
+		current, err := currentApproved(ctx, nft)
+		if err != nil {
+			return nil, err
+		}
+		if current != "" {
+			nfts = append(nfts, nft)
+		}
+	}
+
+	return nfts, nil
+}
+
+// ApprovalResolution tells whether a client can transfer a non-fungible token and what grants it
+// Source is "owner", "token" for the single-token approval, "operator" for an approval for all, or "none"
+type ApprovalResolution struct {
+	TokenID   string `json:"tokenId"`
+	Candidate string `json:"candidate"`
+	Allowed   bool   `json:"allowed"`
+	Source    string `json:"source"`
+}
+
+// ResolveApproval returns whether candidate can transfer a non-fungible token, and the first source, in the
+// order checked by TransferFrom, that allows it. An expired single-token approval grants nothing.
+func (s *SmartContract) ResolveApproval(ctx contractapi.TransactionContextInterface, tokenID string, candidate string) (*ApprovalResolution, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if nft.isEdition() {
+		return nil, fmt.Errorf("token %s is an edition of %d copies without a single owner", tokenID, nft.Supply)
+	}
+
+	resolution := &ApprovalResolution{TokenID: tokenID, Candidate: candidate, Allowed: true}
+	if nft.Owner == candidate {
+		resolution.Source = "owner"
+		return resolution, nil
+	}
+
+	approved, err := currentApproved(ctx, nft)
+	if err != nil {
+		return nil, err
+	}
+	if approved == candidate {
+		resolution.Source = "token"
+		return resolution, nil
+	}
+
+	operatorApproval, err := isApprovedForAll(ctx, nft.Owner, candidate)
+	if err != nil {
+		return nil, err
+	}
+	if operatorApproval {
+		resolution.Source = "operator"
+		return resolution, nil
+	}
+
+	resolution.Allowed = false
+	resolution.Source = "none"
+	return resolution, nil
+}
+
+// GetFullApprovalStatus returns every client allowed to transfer a non-fungible token besides its owner:
+// the approved client of the token, empty if none or expired, and the operators approved by the owner
+func (s *SmartContract) GetFullApprovalStatus(ctx contractapi.TransactionContextInterface, tokenID string) (*ApprovalStatus, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if nft.isEdition() {
+		return nil, fmt.Errorf("token %s is an edition of %d copies without a single owner", tokenID, nft.Supply)
+	}
+
+	approved, err := currentApproved(ctx, nft)
+	if err != nil {
+		return nil, err
+	}
+
+	operators, err := approvedOperators(ctx, nft.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApprovalStatus{TokenID: tokenID, Owner: nft.Owner, Approved: approved, Operators: operators}, nil
+}
+
+// CountApprovedOperators returns how many operators an owner currently approves
+func (s *SmartContract) CountApprovedOperators(ctx contractapi.TransactionContextInterface, owner string) (int, error) {
+	operators, err := approvedOperators(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(operators), nil
+}
+
+// GetTokensApprovedTo returns the non-fungible tokens a client is the approved client of
+// Every nft record is visited, since approvals are stored on the token rather than indexed by client.
+// Expired approvals are not reported.
+func (s *SmartContract) GetTokensApprovedTo(ctx contractapi.TransactionContextInterface, approved string) ([]*Nft, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(nftPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nft keys: %v", err)
+	}
+	defer iterator.Close()
+
+	nfts := []*Nft{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read nft key: %v", err)
+		}
+
+		var nft Nft
+		err = json.Unmarshal(queryResponse.Value, &nft)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal token %s: %v", queryResponse.Key, err)
+		}
+
+		current, err := currentApproved(ctx, &nft)
+		if err != nil {
+			return nil, err
+		}
+		if current != "" && current == approved {
+			nfts = append(nfts, &nft)
+		}
+	}
+
+	return nfts, nil
+}
+
+// SetApprovalForAllBatch approves or removes several operators of the calling owner in one transaction
+// Every change is recorded in the approval log as if SetApprovalForAll had been called for each operator.
+// This function triggers a single ApprovalForAllBatch event listing the operators
+func (s *SmartContract) SetApprovalForAllBatch(ctx contractapi.TransactionContextInterface, operators []string, approved bool) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(operators) == 0 {
+		return fmt.Errorf("no operators were given")
+	}
+	err = checkBatchSize(ctx, len(operators))
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(operators))
+	for _, operator := range operators {
+		if seen[operator] {
+			return fmt.Errorf("operator %s appears more than once", operator)
+		}
+		seen[operator] = true
+	}
+
+	err = setApprovalForAllHelper(ctx, sender, operators, approved)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "ApprovalForAllBatch", events.ApprovalForAllBatchEvent{Owner: sender, Operators: operators, Approved: approved})
+}
+
+// GetApprovalForAllEvents returns the operator grants and revokes of an owner, oldest first
+// Pass the returned bookmark to fetch the next page.
+func (s *SmartContract) GetApprovalForAllEvents(ctx contractapi.TransactionContextInterface, owner string, pageSize int, bookmark string) (*ApprovalForAllChangesPage, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
+	}
+
+	// There is a key record for every change in the format of approvalLogPrefix.owner.sequence.txId.operator,
+	// with the sequence zero-padded so that the records of an owner sort in order
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(approvalLogPrefix, []string{owner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval log keys for owner %s: %v", owner, err)
+	}
+	defer iterator.Close()
+
+	page := &ApprovalForAllChangesPage{Records: []ApprovalForAllChange{}}
+	lastKey := ""
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read approval log key: %v", err)
+		}
+
+		// The bookmark is the key of the last record of the previous page
+		if bookmark != "" && queryResponse.Key <= bookmark {
+			continue
+		}
+
+		// Stop once the page is full; the remaining records belong to the next page
+		if len(page.Records) == pageSize {
+			page.Bookmark = lastKey
+			break
+		}
+
+		var change ApprovalForAllChange
+		err = json.Unmarshal(queryResponse.Value, &change)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal approval log record %s: %v", queryResponse.Key, err)
+		}
+		page.Records = append(page.Records, change)
+		lastKey = queryResponse.Key
+	}
+
+	return page, nil
+}
+
+// Helper Functions
+
+// approvedOperators returns the operators an owner currently approves
+// There is a key record for every operator an owner ever approved or revoked in the format of approvalPrefix.owner.operator.
+// Revoked operators are kept with Approved set to false, so they are filtered out here.
+func approvedOperators(ctx contractapi.TransactionContextInterface, owner string) ([]string, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(approvalPrefix, []string{owner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval keys for owner %s: %v", owner, err)
+	}
+	defer iterator.Close()
+
+	operators := []string{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read approval key: %v", err)
+		}
+
+		var approval Approval
+		err = json.Unmarshal(queryResponse.Value, &approval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal approval %s: %v", queryResponse.Key, err)
+		}
+		if approval.Approved {
+			operators = append(operators, approval.Operator)
+		}
+	}
+
+	return operators, nil
+}
+
+// appendApprovalLog records grants or revokes of operators, in the given order, in the approval log of their owner
+// Transaction times are set by the client and need not increase, so the key holds a sequence number counted per owner
+// and the time is only kept in the record. All changes of a transaction are logged in one call, since a transaction
+// does not read its own update of the sequence.
+func appendApprovalLog(ctx contractapi.TransactionContextInterface, owner string, operators []string, approved bool) error {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	sequenceKey, err := ctx.GetStub().CreateCompositeKey(approvalLogSequencePrefix, []string{owner})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", approvalLogSequencePrefix, err)
+	}
+	sequenceBytes, err := ctx.GetStub().GetState(sequenceKey)
+	if err != nil {
+		return fmt.Errorf("failed to read approval log sequence of %s: %v", owner, err)
+	}
+	sequence := 0
+	if len(sequenceBytes) > 0 {
+		sequence, _ = strconv.Atoi(string(sequenceBytes)) // Error handling not needed since Itoa() was used when setting the sequence, guaranteeing it was an integer.
+	}
+
+	for _, operator := range operators {
+		sequence++
+		change := ApprovalForAllChange{Owner: owner, Operator: operator, Approved: approved, Timestamp: timestamp.Seconds, TxID: ctx.GetStub().GetTxID()}
+		logKey, err := ctx.GetStub().CreateCompositeKey(approvalLogPrefix, []string{owner, fmt.Sprintf("%020d", sequence), change.TxID, operator})
+		if err != nil {
+			return fmt.Errorf("failed to create the composite key for prefix %s: %v", approvalLogPrefix, err)
+		}
+		changeJSON, err := json.Marshal(change)
+		if err != nil {
+			return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		}
+		err = ctx.GetStub().PutState(logKey, changeJSON)
+		if err != nil {
+			return fmt.Errorf("failed to put approval log record %s: %v", logKey, err)
+		}
+	}
+
+	err = ctx.GetStub().PutState(sequenceKey, []byte(strconv.Itoa(sequence)))
+	if err != nil {
+		return fmt.Errorf("failed to put approval log sequence of %s: %v", owner, err)
+	}
+
+	return nil
+}
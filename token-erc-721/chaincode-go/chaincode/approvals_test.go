@@ -0,0 +1,329 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTokensApprovedTo(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103", "104")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	setTxTime(stub, 1000)
+	_, err := tokenContract.Approve(minterCtx, operator, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.Approve(minterCtx, operator, "103")
+	require.NoError(t, err)
+	_, err = tokenContract.Approve(minterCtx, recipient, "102")
+	require.NoError(t, err)
+	_, err = tokenContract.ApproveWithExpiry(minterCtx, operator, "104", 1500)
+	require.NoError(t, err)
+
+	tokenIDs := func(nfts []*chaincode.Nft) []string {
+		ids := []string{}
+		for _, nft := range nfts {
+			ids = append(ids, nft.TokenID)
+		}
+		return ids
+	}
+
+	nfts, err := tokenContract.GetTokensApprovedTo(minterCtx, operator)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"101", "103", "104"}, tokenIDs(nfts))
+
+	nfts, err = tokenContract.GetTokensApprovedTo(minterCtx, recipient)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"102"}, tokenIDs(nfts))
+
+	// Expired approvals and tokens without an approved client are not reported
+	setTxTime(stub, 1500)
+	nfts, err = tokenContract.GetTokensApprovedTo(minterCtx, operator)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"101", "103"}, tokenIDs(nfts))
+
+	nfts, err = tokenContract.GetTokensApprovedTo(minterCtx, "")
+	require.NoError(t, err)
+	require.Empty(t, nfts)
+}
+
+func TestGetFullApprovalStatus(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	status, err := tokenContract.GetFullApprovalStatus(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ApprovalStatus{TokenID: "101", Owner: minter, Operators: []string{}}, status)
+
+	_, err = tokenContract.Approve(minterCtx, recipient, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.SetApprovalForAll(minterCtx, operator, true)
+	require.NoError(t, err)
+	_, err = tokenContract.SetApprovalForAll(minterCtx, "marketplace", true)
+	require.NoError(t, err)
+	_, err = tokenContract.SetApprovalForAll(minterCtx, "marketplace", false)
+	require.NoError(t, err)
+
+	// Operators of other owners are not reported
+	_, err = tokenContract.SetApprovalForAll(prepMocks(stub, org2MSP, recipient), "other", true)
+	require.NoError(t, err)
+
+	status, err = tokenContract.GetFullApprovalStatus(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, recipient, status.Approved)
+	require.Equal(t, []string{operator}, status.Operators)
+
+	_, err = tokenContract.GetFullApprovalStatus(minterCtx, "999")
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
+
+func TestCountApprovedOperators(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	count, err := tokenContract.CountApprovedOperators(minterCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	for _, operator := range []string{"operator1", "operator2", "operator3"} {
+		_, err = tokenContract.SetApprovalForAll(minterCtx, operator, true)
+		require.NoError(t, err)
+	}
+	_, err = tokenContract.SetApprovalForAll(minterCtx, "operator2", false)
+	require.NoError(t, err)
+
+	count, err = tokenContract.CountApprovedOperators(minterCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+}
+
+func TestGetApprovalForAllEvents(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	changes := []struct {
+		txID     string
+		time     int64
+		operator string
+		approved bool
+	}{
+		{"grantTx", 1000, operator, true},
+		{"revokeTx", 2000, operator, false},
+		{"otherTx", 3000, recipient, true},
+	}
+	for _, change := range changes {
+		stub.MockTransactionStart(change.txID)
+		setTxTime(stub, change.time)
+		_, err := tokenContract.SetApprovalForAll(minterCtx, change.operator, change.approved)
+		require.NoError(t, err)
+		stub.MockTransactionEnd(change.txID)
+	}
+
+	page, err := tokenContract.GetApprovalForAllEvents(minterCtx, minter, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.ApprovalForAllChange{
+		{Owner: minter, Operator: operator, Approved: true, Timestamp: 1000, TxID: "grantTx"},
+		{Owner: minter, Operator: operator, Approved: false, Timestamp: 2000, TxID: "revokeTx"},
+	}, page.Records)
+	require.NotEmpty(t, page.Bookmark)
+
+	page, err = tokenContract.GetApprovalForAllEvents(minterCtx, minter, 2, page.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.ApprovalForAllChange{
+		{Owner: minter, Operator: recipient, Approved: true, Timestamp: 3000, TxID: "otherTx"},
+	}, page.Records)
+	require.Empty(t, page.Bookmark)
+
+	// Other owners have their own log
+	page, err = tokenContract.GetApprovalForAllEvents(minterCtx, recipient, 2, "")
+	require.NoError(t, err)
+	require.Empty(t, page.Records)
+
+	_, err = tokenContract.GetApprovalForAllEvents(minterCtx, minter, 0, "")
+	require.EqualError(t, err, "page size must be a positive integer")
+}
+
+func TestGetApprovalForAllEventsSameSecond(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	// The transaction IDs sort against the order of the changes, which is kept by the per-owner sequence
+	for _, change := range []struct {
+		txID     string
+		approved bool
+	}{
+		{"txB", true},
+		{"txA", false},
+	} {
+		stub.MockTransactionStart(change.txID)
+		setTxTime(stub, 1000)
+		_, err := tokenContract.SetApprovalForAll(minterCtx, operator, change.approved)
+		require.NoError(t, err)
+		stub.MockTransactionEnd(change.txID)
+	}
+
+	page, err := tokenContract.GetApprovalForAllEvents(minterCtx, minter, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.ApprovalForAllChange{
+		{Owner: minter, Operator: operator, Approved: true, Timestamp: 1000, TxID: "txB"},
+		{Owner: minter, Operator: operator, Approved: false, Timestamp: 1000, TxID: "txA"},
+	}, page.Records)
+}
+
+func TestGetApprovalForAllEventsOutOfOrderTime(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	// A later transaction may carry an earlier client timestamp, the log keeps the order the changes were committed in
+	for _, change := range []struct {
+		txID     string
+		seconds  int64
+		approved bool
+	}{
+		{"tx1", 2000, true},
+		{"tx2", 1000, false},
+	} {
+		stub.MockTransactionStart(change.txID)
+		setTxTime(stub, change.seconds)
+		_, err := tokenContract.SetApprovalForAll(minterCtx, operator, change.approved)
+		require.NoError(t, err)
+		stub.MockTransactionEnd(change.txID)
+	}
+
+	page, err := tokenContract.GetApprovalForAllEvents(minterCtx, minter, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.ApprovalForAllChange{
+		{Owner: minter, Operator: operator, Approved: true, Timestamp: 2000, TxID: "tx1"},
+		{Owner: minter, Operator: operator, Approved: false, Timestamp: 1000, TxID: "tx2"},
+	}, page.Records)
+}
+
+func TestSetApprovalForAllBatch(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetApprovalForAllBatch(minterCtx, []string{}, true)
+	require.EqualError(t, err, "no operators were given")
+	err = tokenContract.SetApprovalForAllBatch(minterCtx, []string{operator, operator}, true)
+	require.EqualError(t, err, "operator "+operator+" appears more than once")
+
+	// The maximum batch size applies to the operators
+	require.NoError(t, tokenContract.SetMaxBatchSize(minterCtx, 2))
+	err = tokenContract.SetApprovalForAllBatch(minterCtx, []string{operator, recipient, "market3"}, true)
+	require.EqualError(t, err, "batch of 3 entries exceeds the maximum batch size of 2")
+	require.NoError(t, tokenContract.SetMaxBatchSize(minterCtx, 3))
+
+	operators := []string{operator, recipient, "market3"}
+	drainEvents(stub)
+	err = tokenContract.SetApprovalForAllBatch(minterCtx, operators, true)
+	require.NoError(t, err)
+
+	for _, op := range operators {
+		approved, err := tokenContract.IsApprovedForAll(minterCtx, minter, op)
+		require.NoError(t, err)
+		require.True(t, approved)
+	}
+
+	// Every operator is recorded in the approval log in the given order, though they share a transaction
+	page, err := tokenContract.GetApprovalForAllEvents(minterCtx, minter, 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Records, 3)
+	for i, op := range operators {
+		require.Equal(t, op, page.Records[i].Operator)
+	}
+
+	// A single event lists all operators
+	emitted := drainEvents(stub)
+	require.Len(t, emitted, 1)
+	require.Equal(t, "ApprovalForAllBatch", emitted[0].EventName)
+	event, err := events.DecodeApprovalForAllBatchEvent(emitted[0].Payload)
+	require.NoError(t, err)
+	require.Equal(t, events.ApprovalForAllBatchEvent{Owner: minter, Operators: operators, Approved: true}, event)
+
+	err = tokenContract.SetApprovalForAllBatch(minterCtx, operators[:2], false)
+	require.NoError(t, err)
+	approved, err := tokenContract.IsApprovedForAll(minterCtx, minter, recipient)
+	require.NoError(t, err)
+	require.False(t, approved)
+}
+
+func TestResolveApproval(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	resolve := func(candidate string) *chaincode.ApprovalResolution {
+		resolution, err := tokenContract.ResolveApproval(minterCtx, "101", candidate)
+		require.NoError(t, err)
+		return resolution
+	}
+
+	require.Equal(t, &chaincode.ApprovalResolution{TokenID: "101", Candidate: minter, Allowed: true, Source: "owner"}, resolve(minter))
+	require.Equal(t, &chaincode.ApprovalResolution{TokenID: "101", Candidate: operator, Allowed: false, Source: "none"}, resolve(operator))
+
+	_, err := tokenContract.SetApprovalForAll(minterCtx, operator, true)
+	require.NoError(t, err)
+	require.Equal(t, "operator", resolve(operator).Source)
+
+	// The single-token approval is checked before the operator approval
+	setTxTime(stub, 1000)
+	_, err = tokenContract.ApproveWithExpiry(minterCtx, operator, "101", 2000)
+	require.NoError(t, err)
+	require.Equal(t, "token", resolve(operator).Source)
+
+	setTxTime(stub, 2000)
+	require.Equal(t, "operator", resolve(operator).Source)
+
+	_, err = tokenContract.ResolveApproval(minterCtx, "999", operator)
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
+
+func TestGetApprovalsExpiringBefore(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103", "104")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	setTxTime(stub, 1000)
+	_, err := tokenContract.ApproveWithExpiry(minterCtx, operator, "101", 1500)
+	require.NoError(t, err)
+	_, err = tokenContract.ApproveWithExpiry(minterCtx, operator, "102", 5000)
+	require.NoError(t, err)
+	_, err = tokenContract.ApproveWithExpiry(minterCtx, operator, "103", 1200)
+	require.NoError(t, err)
+	_, err = tokenContract.Approve(minterCtx, operator, "104")
+	require.NoError(t, err)
+	_, err = tokenContract.SetApprovalForAll(minterCtx, recipient, true)
+	require.NoError(t, err)
+
+	expiring := func(before int64) []string {
+		nfts, err := tokenContract.GetApprovalsExpiringBefore(minterCtx, minter, before)
+		require.NoError(t, err)
+		tokenIDs := []string{}
+		for _, nft := range nfts {
+			tokenIDs = append(tokenIDs, nft.TokenID)
+		}
+		return tokenIDs
+	}
+
+	require.Equal(t, []string{"101", "103"}, expiring(2000))
+	require.Equal(t, []string{"101", "102", "103"}, expiring(6000))
+
+	// Approvals that already lapsed are no longer reported
+	setTxTime(stub, 1300)
+	require.Equal(t, []string{"101"}, expiring(2000))
+
+	require.Empty(t, expiring(1000))
+}
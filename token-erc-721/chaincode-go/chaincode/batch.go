@@ -0,0 +1,133 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define key names for options
+const maxBatchSizeKey = "maxBatchSize"
+
+// defaultMaxBatchSize is used until an admin configures a maximum batch size
+const defaultMaxBatchSize = 100
+
+// ApprovedBatch is the result of GetApprovedBatch
+// Approved maps every existing token to its approved client, empty if none. Missing lists the token IDs that do not exist.
+type ApprovedBatch struct {
+	Approved map[string]string `json:"approved"`
+	Missing  []string          `json:"missing"`
+}
+
+// SetMaxBatchSize sets the largest number of entries a function given a list of tokens or accounts accepts
+// Oversized batches could otherwise produce transactions or responses too large for the ordering service.
+func (s *SmartContract) SetMaxBatchSize(ctx contractapi.TransactionContextInterface, n int) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if n <= 0 {
+		return fmt.Errorf("maximum batch size must be a positive integer")
+	}
+
+	err = ctx.GetStub().PutState(maxBatchSizeKey, []byte(strconv.Itoa(n)))
+	if err != nil {
+		return fmt.Errorf("failed to set maximum batch size: %v", err)
+	}
+
+	return nil
+}
+
+// HydratedToken is one entry of the result of HydrateTokens
+// Found is false, and Token is nil, for a token ID that does not exist.
+// ResolvedURI follows ResolveTokenURI, and is empty if neither a token URI nor a base URI is set.
+type HydratedToken struct {
+	TokenID     string `json:"tokenId"`
+	Found       bool   `json:"found"`
+	Token       *Nft   `json:"token,omitempty" metadata:",optional"`
+	ResolvedURI string `json:"resolvedURI,omitempty" metadata:",optional"`
+}
+
+// HydrateTokens returns the token data and resolved URI of every given token ID, in the given order
+// Missing tokens are reported as not found rather than failing the whole call.
+func (s *SmartContract) HydrateTokens(ctx contractapi.TransactionContextInterface, tokenIDs []string) ([]HydratedToken, error) {
+	err := checkBatchSize(ctx, len(tokenIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	baseURI, err := readBaseURI(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hydrated := make([]HydratedToken, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		nft, err := findNFT(ctx, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		if nft == nil {
+			hydrated = append(hydrated, HydratedToken{TokenID: tokenID})
+			continue
+		}
+
+		resolvedURI := nft.TokenURI
+		if resolvedURI == "" && baseURI != "" {
+			resolvedURI = baseURI + tokenID
+		}
+
+		hydrated = append(hydrated, HydratedToken{TokenID: tokenID, Found: true, Token: nft, ResolvedURI: resolvedURI})
+	}
+
+	return hydrated, nil
+}
+
+// GetApprovedBatch returns the approved client of every given token, reading each token once
+// Missing tokens are listed in the result rather than failing the whole call.
+func (s *SmartContract) GetApprovedBatch(ctx contractapi.TransactionContextInterface, tokenIDs []string) (*ApprovedBatch, error) {
+	err := checkBatchSize(ctx, len(tokenIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &ApprovedBatch{Approved: map[string]string{}, Missing: []string{}}
+	for _, tokenID := range tokenIDs {
+		nft, err := findNFT(ctx, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		if nft == nil {
+			batch.Missing = append(batch.Missing, tokenID)
+			continue
+		}
+
+		batch.Approved[tokenID], err = currentApproved(ctx, nft)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return batch, nil
+}
+
+// Helper Functions
+
+// checkBatchSize rejects a batch of more entries than the configured maximum batch size
+func checkBatchSize(ctx contractapi.TransactionContextInterface, size int) error {
+	max, err := readIntOption(ctx, maxBatchSizeKey)
+	if err != nil {
+		return err
+	}
+	if max == 0 {
+		max = defaultMaxBatchSize
+	}
+
+	if size > max {
+		return fmt.Errorf("batch of %d entries exceeds the maximum batch size of %d", size, max)
+	}
+
+	return nil
+}
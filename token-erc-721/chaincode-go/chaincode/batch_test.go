@@ -0,0 +1,87 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHydrateTokens(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.MintWithTokenURI(minterCtx, "102", "")
+	require.NoError(t, err)
+
+	hydrated, err := tokenContract.HydrateTokens(minterCtx, []string{"102", "999", "101"})
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.HydratedToken{
+		{TokenID: "102", Found: true, Token: &chaincode.Nft{TokenID: "102", Owner: minter, Creator: minter, MintTxID: "tx1"}},
+		{TokenID: "999"},
+		{TokenID: "101", Found: true, Token: &chaincode.Nft{TokenID: "101", Owner: minter, Creator: minter, MintTxID: "tx1", TokenURI: "https://example.com/nft/101"}, ResolvedURI: "https://example.com/nft/101"},
+	}, hydrated)
+
+	// Tokens without their own URI resolve against the base URI
+	err = tokenContract.SetBaseURI(minterCtx, "https://example.com/base/")
+	require.NoError(t, err)
+	hydrated, err = tokenContract.HydrateTokens(minterCtx, []string{"102"})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/base/102", hydrated[0].ResolvedURI)
+}
+
+func TestGetApprovedBatch(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	setTxTime(stub, 1000)
+	_, err := tokenContract.Approve(minterCtx, operator, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.ApproveWithExpiry(minterCtx, operator, "103", 1000)
+	require.NoError(t, err)
+
+	batch, err := tokenContract.GetApprovedBatch(minterCtx, []string{"101", "102", "103", "999"})
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ApprovedBatch{
+		Approved: map[string]string{"101": operator, "102": "", "103": ""},
+		Missing:  []string{"999"},
+	}, batch)
+}
+
+func TestMaxBatchSize(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	// The default limit applies until one is configured
+	oversized := make([]string, 101)
+	for i := range oversized {
+		oversized[i] = "101"
+	}
+	_, err := tokenContract.HydrateTokens(minterCtx, oversized)
+	require.EqualError(t, err, "batch of 101 entries exceeds the maximum batch size of 100")
+
+	err = tokenContract.SetMaxBatchSize(prepMocks(stub, org2MSP, recipient), 2)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+	err = tokenContract.SetMaxBatchSize(minterCtx, 0)
+	require.EqualError(t, err, "maximum batch size must be a positive integer")
+
+	err = tokenContract.SetMaxBatchSize(minterCtx, 2)
+	require.NoError(t, err)
+
+	tokenIDs := []string{"101", "102", "103"}
+	_, err = tokenContract.HydrateTokens(minterCtx, tokenIDs)
+	require.EqualError(t, err, "batch of 3 entries exceeds the maximum batch size of 2")
+	_, err = tokenContract.GetApprovedBatch(minterCtx, tokenIDs)
+	require.EqualError(t, err, "batch of 3 entries exceeds the maximum batch size of 2")
+	err = tokenContract.RevealMetadata(minterCtx, tokenIDs, []string{"a", "b", "c"})
+	require.EqualError(t, err, "batch of 3 entries exceeds the maximum batch size of 2")
+
+	_, err = tokenContract.GetApprovedBatch(minterCtx, tokenIDs[:2])
+	require.NoError(t, err)
+}
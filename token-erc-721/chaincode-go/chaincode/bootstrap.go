@@ -0,0 +1,75 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// BootstrapConfig is the initial configuration of a newly deployed collection
+// This contract has no max supply, royalty or configurable admin, so those settings are not accepted.
+// StrictMode can only be set here, see IsStrictMode.
+type BootstrapConfig struct {
+	Name       string `json:"name"`
+	Symbol     string `json:"symbol"`
+	BaseURI    string `json:"baseURI,omitempty"`
+	StrictMode bool   `json:"strictMode,omitempty"`
+}
+
+// Bootstrap applies the initial configuration of the collection in a single transaction
+// cfg is a JSON encoded BootstrapConfig. Unknown settings and data after the config are rejected rather than ignored.
+// It fails once a name has been set, by an earlier Bootstrap or by SetOption.
+func (s *SmartContract) Bootstrap(ctx contractapi.TransactionContextInterface, cfg string) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(cfg)))
+	decoder.DisallowUnknownFields()
+	var config BootstrapConfig
+	err = decoder.Decode(&config)
+	if err != nil {
+		return fmt.Errorf("failed to decode bootstrap config: %v", err)
+	}
+	if decoder.Decode(&struct{}{}) != io.EOF {
+		return fmt.Errorf("failed to decode bootstrap config: unexpected data after the config")
+	}
+	if config.Name == "" || config.Symbol == "" {
+		return fmt.Errorf("bootstrap config must set a name and symbol")
+	}
+
+	nameBytes, err := ctx.GetStub().GetState(nameKey)
+	if err != nil {
+		return fmt.Errorf("failed to get name: %v", err)
+	}
+	if len(nameBytes) > 0 {
+		return fmt.Errorf("the contract is already initialized")
+	}
+
+	err = ctx.GetStub().PutState(nameKey, []byte(config.Name))
+	if err != nil {
+		return fmt.Errorf("failed to set name: %v", err)
+	}
+	err = ctx.GetStub().PutState(symbolKey, []byte(config.Symbol))
+	if err != nil {
+		return fmt.Errorf("failed to set symbol: %v", err)
+	}
+	if config.BaseURI != "" {
+		err = ctx.GetStub().PutState(baseURIKey, []byte(config.BaseURI))
+		if err != nil {
+			return fmt.Errorf("failed to set base URI: %v", err)
+		}
+	}
+	if config.StrictMode {
+		err = ctx.GetStub().PutState(strictModeKey, []byte{0x00})
+		if err != nil {
+			return fmt.Errorf("failed to set strict mode: %v", err)
+		}
+	}
+
+	return nil
+}
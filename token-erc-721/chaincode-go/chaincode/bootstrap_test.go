@@ -0,0 +1,47 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrap(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.Bootstrap(prepMocks(stub, org2MSP, recipient), `{"name":"Collection","symbol":"COL"}`)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	err = tokenContract.Bootstrap(adminCtx, `{"name":"Collection","symbol":"COL","maxSupply":100}`)
+	require.EqualError(t, err, `failed to decode bootstrap config: json: unknown field "maxSupply"`)
+
+	err = tokenContract.Bootstrap(adminCtx, `{"name":"Collection","symbol":"COL"} {"name":"Other"}`)
+	require.EqualError(t, err, "failed to decode bootstrap config: unexpected data after the config")
+	err = tokenContract.Bootstrap(adminCtx, `{"name":"Collection","symbol":"COL"}}`)
+	require.EqualError(t, err, "failed to decode bootstrap config: unexpected data after the config")
+
+	err = tokenContract.Bootstrap(adminCtx, `{"name":"Collection"}`)
+	require.EqualError(t, err, "bootstrap config must set a name and symbol")
+
+	err = tokenContract.Bootstrap(adminCtx, `{"name":"Collection","symbol":"COL","baseURI":"https://example.com/tokens/"}`+"\n")
+	require.NoError(t, err)
+
+	name, err := tokenContract.Name(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, "Collection", name)
+	symbol, err := tokenContract.Symbol(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, "COL", symbol)
+	baseURI, err := tokenContract.GetBaseURI(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/tokens/", baseURI)
+
+	err = tokenContract.Bootstrap(adminCtx, `{"name":"Other","symbol":"OTH"}`)
+	require.EqualError(t, err, "the contract is already initialized")
+	name, err = tokenContract.Name(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, "Collection", name)
+}
@@ -0,0 +1,86 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define key names for options
+const transferCallbackKey = "transferCallback"
+
+// TransferCallback is the chaincode function TransferFrom invokes after every transfer
+// The function is called with the from, to and tokenId of the transfer as its arguments.
+// A failing strict callback fails the transfer, a failing best-effort callback is only logged.
+type TransferCallback struct {
+	ChaincodeName string `json:"chaincodeName"`
+	FunctionName  string `json:"functionName"`
+	BestEffort    bool   `json:"bestEffort"`
+}
+
+// SetTransferCallback configures the chaincode function to notify of transfers, on the same channel
+// An empty chaincodeName removes the callback
+func (s *SmartContract) SetTransferCallback(ctx contractapi.TransactionContextInterface, chaincodeName string, fnName string, bestEffort bool) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if chaincodeName == "" {
+		err = ctx.GetStub().DelState(transferCallbackKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete transfer callback: %v", err)
+		}
+		return nil
+	}
+
+	if fnName == "" {
+		return fmt.Errorf("callback function name must not be empty")
+	}
+
+	callbackJSON, err := json.Marshal(TransferCallback{chaincodeName, fnName, bestEffort})
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(transferCallbackKey, callbackJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set transfer callback: %v", err)
+	}
+
+	return nil
+}
+
+// Helper Functions
+
+// invokeTransferCallback notifies the configured chaincode of a transfer, if any
+// Since the callback runs in the same transaction, returning its failure rolls back the transfer
+func invokeTransferCallback(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) error {
+	callbackBytes, err := ctx.GetStub().GetState(transferCallbackKey)
+	if err != nil {
+		return fmt.Errorf("failed to read transfer callback: %v", err)
+	}
+	if len(callbackBytes) == 0 {
+		return nil
+	}
+
+	var callback TransferCallback
+	err = json.Unmarshal(callbackBytes, &callback)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal transfer callback: %v", err)
+	}
+
+	args := [][]byte{[]byte(callback.FunctionName), []byte(from), []byte(to), []byte(tokenID)}
+	response := ctx.GetStub().InvokeChaincode(callback.ChaincodeName, args, "")
+	if response.Status != shim.OK {
+		if callback.BestEffort {
+			log.Printf("transfer callback %s.%s failed for token %s: %s", callback.ChaincodeName, callback.FunctionName, tokenID, response.Message)
+			return nil
+		}
+		return fmt.Errorf("transfer callback %s.%s failed: %s", callback.ChaincodeName, callback.FunctionName, response.Message)
+	}
+
+	return nil
+}
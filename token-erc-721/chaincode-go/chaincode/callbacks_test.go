@@ -0,0 +1,70 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+// notificationChaincode records the transfers it is notified of and rejects calls to its reject function
+type notificationChaincode struct {
+	calls [][]string
+}
+
+func (cc *notificationChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
+
+func (cc *notificationChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	fn, args := stub.GetFunctionAndParameters()
+	if fn == "reject" {
+		return shim.Error("transfer rejected by receiver")
+	}
+	cc.calls = append(cc.calls, args)
+	return shim.Success(nil)
+}
+
+func TestTransferCallback(t *testing.T) {
+	stub := newMockStub()
+	notifications := &notificationChaincode{}
+	stub.MockPeerChaincode("rewards", shimtest.NewMockStub("rewards", notifications), "")
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetTransferCallback(prepMocks(stub, org2MSP, recipient), "rewards", "notify", false)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	err = tokenContract.SetTransferCallback(minterCtx, "rewards", "", false)
+	require.EqualError(t, err, "callback function name must not be empty")
+
+	// A strict callback is invoked with the transfer details
+	err = tokenContract.SetTransferCallback(minterCtx, "rewards", "notify", false)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{minter, recipient, "101"}}, notifications.calls)
+
+	// A failing strict callback fails the transfer
+	err = tokenContract.SetTransferCallback(minterCtx, "rewards", "reject", false)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "102")
+	require.EqualError(t, err, "transfer callback rewards.reject failed: transfer rejected by receiver")
+
+	// A failing best-effort callback does not
+	err = tokenContract.SetTransferCallback(minterCtx, "rewards", "reject", true)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "103")
+	require.NoError(t, err)
+
+	// Removing the callback stops notifications
+	err = tokenContract.SetTransferCallback(minterCtx, "", "", false)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(prepMocks(stub, org2MSP, recipient), recipient, minter, "101")
+	require.NoError(t, err)
+	require.Len(t, notifications.calls, 1)
+}
@@ -0,0 +1,132 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const transferLimitPrefix = "transferLimit"
+
+// TransferLimit is the number of times a token may change hands, and how often it already has
+// MaxTransfers is zero for a token that circulates without a limit
+type TransferLimit struct {
+	TokenID      string `json:"tokenId"`
+	MaxTransfers int    `json:"maxTransfers"`
+	Transfers    int    `json:"transfers"`
+}
+
+// MintWithTransferLimit creates a new non-fungible token that can be transferred at most maxTransfers times
+// TransferFrom, TransferSale and AcceptTransfer count against the limit, CompleteRecovery does not,
+// since recovery is the escape hatch for a lost key.
+// This function triggers a Transfer event
+func (s *SmartContract) MintWithTransferLimit(ctx contractapi.TransactionContextInterface, tokenID string, tokenURI string, maxTransfers int) (*Nft, error) {
+	if maxTransfers <= 0 {
+		return nil, fmt.Errorf("maximum number of transfers must be a positive integer")
+	}
+
+	nft, err := mintHelper(ctx, tokenID, tokenURI, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	err = putTransferLimit(ctx, &TransferLimit{TokenID: tokenID, MaxTransfers: maxTransfers})
+	if err != nil {
+		return nil, err
+	}
+
+	return nft, nil
+}
+
+// GetTransferLimit returns the transfer limit of a token and the number of transfers counted against it
+func (s *SmartContract) GetTransferLimit(ctx contractapi.TransactionContextInterface, tokenID string) (*TransferLimit, error) {
+	_, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := readTransferLimit(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if limit == nil {
+		return &TransferLimit{TokenID: tokenID}, nil
+	}
+
+	return limit, nil
+}
+
+// Helper Functions
+
+// countTransfer counts a transfer against the limit of a token, and fails once the limit is reached
+// Tokens without a limit are not counted.
+func countTransfer(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	limit, err := readTransferLimit(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if limit == nil {
+		return nil
+	}
+	if limit.Transfers >= limit.MaxTransfers {
+		return fmt.Errorf("token %s has reached its limit of %d transfers", tokenID, limit.MaxTransfers)
+	}
+
+	limit.Transfers++
+	return putTransferLimit(ctx, limit)
+}
+
+// readTransferLimit returns the transfer limit of a token, or nil if it has none
+func readTransferLimit(ctx contractapi.TransactionContextInterface, tokenID string) (*TransferLimit, error) {
+	limitKey, err := ctx.GetStub().CreateCompositeKey(transferLimitPrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", transferLimitPrefix, err)
+	}
+	limitBytes, err := ctx.GetStub().GetState(limitKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transfer limit of token %s: %v", tokenID, err)
+	}
+	if len(limitBytes) == 0 {
+		return nil, nil
+	}
+
+	var limit TransferLimit
+	err = json.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transfer limit of token %s: %v", tokenID, err)
+	}
+
+	return &limit, nil
+}
+
+func putTransferLimit(ctx contractapi.TransactionContextInterface, limit *TransferLimit) error {
+	limitKey, err := ctx.GetStub().CreateCompositeKey(transferLimitPrefix, []string{limit.TokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", transferLimitPrefix, err)
+	}
+	limitJSON, err := json.Marshal(limit)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(limitKey, limitJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put transfer limit of token %s: %v", limit.TokenID, err)
+	}
+
+	return nil
+}
+
+func deleteTransferLimit(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	limitKey, err := ctx.GetStub().CreateCompositeKey(transferLimitPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", transferLimitPrefix, err)
+	}
+	err = ctx.GetStub().DelState(limitKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete transfer limit of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
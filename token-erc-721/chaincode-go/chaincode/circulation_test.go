@@ -0,0 +1,59 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferLimit(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	_, err := tokenContract.MintWithTransferLimit(minterCtx, "101", "https://example.com/nft/101", 0)
+	require.EqualError(t, err, "maximum number of transfers must be a positive integer")
+
+	_, err = tokenContract.MintWithTransferLimit(minterCtx, "101", "https://example.com/nft/101", 2)
+	require.NoError(t, err)
+	mintTokens(t, stub, "102")
+
+	// The second transfer is the last one allowed
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(recipientCtx, recipient, minter, "101")
+	require.NoError(t, err)
+
+	limit, err := tokenContract.GetTransferLimit(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TransferLimit{TokenID: "101", MaxTransfers: 2, Transfers: 2}, limit)
+
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.EqualError(t, err, "token 101 has reached its limit of 2 transfers")
+	err = tokenContract.OfferTransfer(minterCtx, recipient, "101")
+	require.NoError(t, err)
+	err = tokenContract.AcceptTransfer(recipientCtx, "101")
+	require.EqualError(t, err, "token 101 has reached its limit of 2 transfers")
+
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, minter, owner)
+
+	// Tokens minted without a limit are not counted
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "102")
+	require.NoError(t, err)
+	limit, err = tokenContract.GetTransferLimit(minterCtx, "102")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TransferLimit{TokenID: "102"}, limit)
+
+	// The limit does not carry over to a token minted later with the same ID
+	_, err = tokenContract.Burn(minterCtx, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "101", "")
+	require.NoError(t, err)
+	limit, err = tokenContract.GetTransferLimit(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TransferLimit{TokenID: "101"}, limit)
+}
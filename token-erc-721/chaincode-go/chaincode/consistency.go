@@ -0,0 +1,126 @@
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ConsistencyReport is the result of CheckTokenConsistency, Problems is empty when Consistent is true
+type ConsistencyReport struct {
+	TokenID    string   `json:"tokenId"`
+	Consistent bool     `json:"consistent"`
+	Problems   []string `json:"problems"`
+}
+
+// CheckTokenConsistency verifies the invariants that tie the records of a non-fungible token together
+// It checks that the token record exists, that the balance keys match its owner or supply, that its approval is
+// well-formed, that the creator index holds it, and that the minted and burned counters reconcile with the
+// number of live tokens. It is a read-only diagnostic for operators and visits every balance and nft key.
+func (s *SmartContract) CheckTokenConsistency(ctx contractapi.TransactionContextInterface, tokenID string) (*ConsistencyReport, error) {
+	report := &ConsistencyReport{TokenID: tokenID, Problems: []string{}}
+
+	nft, err := findNFT(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	holders, err := tokenHolders(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report holders in a stable order
+	accounts := make([]string, 0, len(holders))
+	for account := range holders {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	if nft == nil {
+		report.Problems = append(report.Problems, "the token record does not exist")
+		for _, account := range accounts {
+			report.Problems = append(report.Problems, fmt.Sprintf("balance key of %s is left for a token that does not exist", account))
+		}
+	} else {
+		problems, err := checkTokenRecords(ctx, nft, holders, accounts)
+		if err != nil {
+			return nil, err
+		}
+		report.Problems = append(report.Problems, problems...)
+	}
+
+	minted, err := readIntOption(ctx, totalMintedKey)
+	if err != nil {
+		return nil, err
+	}
+	burned, err := readIntOption(ctx, totalBurnedKey)
+	if err != nil {
+		return nil, err
+	}
+	supply, err := s.TotalSupply(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if minted-burned != supply {
+		report.Problems = append(report.Problems, fmt.Sprintf("%d minted minus %d burned does not match the %d live tokens", minted, burned, supply))
+	}
+
+	report.Consistent = len(report.Problems) == 0
+
+	return report, nil
+}
+
+// Helper Functions
+
+// checkTokenRecords returns the problems found between an existing token and its related records
+func checkTokenRecords(ctx contractapi.TransactionContextInterface, nft *Nft, holders map[string]int, accounts []string) ([]string, error) {
+	problems := []string{}
+
+	if nft.isEdition() {
+		copies := 0
+		for _, account := range accounts {
+			copies += holders[account]
+		}
+		if copies != nft.Supply {
+			problems = append(problems, fmt.Sprintf("balance keys hold %d copies of an edition of %d", copies, nft.Supply))
+		}
+		if nft.Approved != "" {
+			problems = append(problems, "an edition has an approved client")
+		}
+	} else {
+		if nft.Owner == "" {
+			problems = append(problems, "the token has no owner")
+		} else if holders[nft.Owner] == 0 {
+			problems = append(problems, fmt.Sprintf("the owner %s has no balance key for the token", nft.Owner))
+		}
+		for _, account := range accounts {
+			if account != nft.Owner {
+				problems = append(problems, fmt.Sprintf("balance key of %s is left although %s owns the token", account, nft.Owner))
+			}
+		}
+
+		if nft.Approved != "" && nft.Approved == nft.Owner {
+			problems = append(problems, "the owner is recorded as the approved client")
+		}
+		if nft.Approved == "" && nft.ApprovedUntil != 0 {
+			problems = append(problems, "an approval expiry is recorded without an approved client")
+		}
+	}
+
+	if nft.Creator != "" {
+		creatorKey, err := ctx.GetStub().CreateCompositeKey(creatorPrefix, []string{nft.Creator, nft.TokenID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", creatorPrefix, err)
+		}
+		creatorBytes, err := ctx.GetStub().GetState(creatorKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read creator key %s: %v", creatorKey, err)
+		}
+		if len(creatorBytes) == 0 {
+			problems = append(problems, fmt.Sprintf("the token is missing from the index of its creator %s", nft.Creator))
+		}
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,65 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTokenConsistency(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.MintEdition(minterCtx, "201", "ipfs://edition-201", 3)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "201")
+	require.NoError(t, err)
+
+	for _, tokenID := range []string{"101", "102", "201"} {
+		report, err := tokenContract.CheckTokenConsistency(minterCtx, tokenID)
+		require.NoError(t, err)
+		require.Equal(t, &chaincode.ConsistencyReport{TokenID: tokenID, Consistent: true, Problems: []string{}}, report)
+	}
+
+	// Corrupt token 101: its old owner keeps a balance key, the new owner loses theirs,
+	// and the owner is recorded as approved
+	oldBalanceKey, err := stub.CreateCompositeKey("balance", []string{minter, "101"})
+	require.NoError(t, err)
+	require.NoError(t, stub.PutState(oldBalanceKey, []byte{0x00}))
+	newBalanceKey, err := stub.CreateCompositeKey("balance", []string{recipient, "101"})
+	require.NoError(t, err)
+	require.NoError(t, stub.DelState(newBalanceKey))
+	nftKey, err := stub.CreateCompositeKey("nft", []string{"101"})
+	require.NoError(t, err)
+	require.NoError(t, stub.PutState(nftKey, []byte(`{"tokenId":"101","owner":"recipient","creator":"minter","tokenURI":"","approved":"recipient"}`)))
+
+	report, err := tokenContract.CheckTokenConsistency(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ConsistencyReport{
+		TokenID:    "101",
+		Consistent: false,
+		Problems: []string{
+			"the owner recipient has no balance key for the token",
+			"balance key of minter is left although recipient owns the token",
+			"the owner is recorded as the approved client",
+		},
+	}, report)
+
+	// Deleting a token record leaves its balance key behind and breaks the counters
+	nftKey, err = stub.CreateCompositeKey("nft", []string{"102"})
+	require.NoError(t, err)
+	require.NoError(t, stub.DelState(nftKey))
+
+	report, err = tokenContract.CheckTokenConsistency(minterCtx, "102")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"the token record does not exist",
+		"balance key of minter is left for a token that does not exist",
+		"3 minted minus 0 burned does not match the 2 live tokens",
+	}, report.Problems)
+}
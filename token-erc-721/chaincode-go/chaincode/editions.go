@@ -0,0 +1,167 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// MintEdition creates a new edition token with the given number of copies and assigns all copies to the minter
+// Each TransferFrom of an edition moves a single copy. A supply of 1 mints a regular single token.
+// This function triggers a Transfer event
+func (s *SmartContract) MintEdition(ctx contractapi.TransactionContextInterface, tokenID string, tokenURI string, supply int) (*Nft, error) {
+	return mintHelper(ctx, tokenID, tokenURI, supply)
+}
+
+// BalanceOfEdition returns how many copies of a token an owner holds
+// For a single token this is 1 for its owner and 0 for everybody else
+func (s *SmartContract) BalanceOfEdition(ctx contractapi.TransactionContextInterface, owner string, tokenID string) (int, error) {
+	exists, err := nftExists(ctx, tokenID)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf("the tokenId %s is invalid. It does not exist", tokenID)
+	}
+
+	return readCopies(ctx, owner, tokenID)
+}
+
+// OwnersOfEdition returns every holder of a token with the number of copies they hold
+// A single token has its owner as the only holder, with one copy, like OwnerOf.
+func (s *SmartContract) OwnersOfEdition(ctx contractapi.TransactionContextInterface, tokenID string) (map[string]int, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if !nft.isEdition() {
+		return map[string]int{nft.Owner: 1}, nil
+	}
+
+	return tokenHolders(ctx, tokenID)
+}
+
+// Helper Functions
+
+// isEdition reports whether the token has several copies
+func (nft *Nft) isEdition() bool {
+	return nft.Supply > 1
+}
+
+// parseCopies returns the number of copies recorded in the value of a balance key
+// Single tokens store the null character, editions store the number of copies
+func parseCopies(balanceValue []byte) int {
+	if len(balanceValue) == 0 {
+		return 0
+	}
+	if len(balanceValue) == 1 && balanceValue[0] == 0x00 {
+		return 1
+	}
+
+	copies, _ := strconv.Atoi(string(balanceValue)) // Error handling not needed since Itoa() was used when setting the copies, guaranteeing it was an integer.
+
+	return copies
+}
+
+// readCopies returns the number of copies of a token in the balance of an owner
+func readCopies(ctx contractapi.TransactionContextInterface, owner string, tokenID string) (int, error) {
+	balanceKey, err := ctx.GetStub().CreateCompositeKey(balancePrefix, []string{owner, tokenID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", balancePrefix, err)
+	}
+	balanceBytes, err := ctx.GetStub().GetState(balanceKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read balance key %s: %v", balanceKey, err)
+	}
+
+	return parseCopies(balanceBytes), nil
+}
+
+// tokenHolders returns every account with a balance key for a token and the number of copies it records
+// Balance keys are ordered by owner, so every balance key is visited to find the holders of a token.
+func tokenHolders(ctx contractapi.TransactionContextInterface, tokenID string) (map[string]int, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(balancePrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance keys: %v", err)
+	}
+	defer iterator.Close()
+
+	owners := map[string]int{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read balance key: %v", err)
+		}
+
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split the composite key %s: %v", queryResponse.Key, err)
+		}
+		if compositeKeyParts[1] != tokenID {
+			continue
+		}
+
+		owners[compositeKeyParts[0]] = parseCopies(queryResponse.Value)
+	}
+
+	return owners, nil
+}
+
+// putCopies sets the number of copies of an edition in the balance of an owner, removing the key at zero
+func putCopies(ctx contractapi.TransactionContextInterface, owner string, tokenID string, copies int) error {
+	balanceKey, err := ctx.GetStub().CreateCompositeKey(balancePrefix, []string{owner, tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", balancePrefix, err)
+	}
+
+	if copies == 0 {
+		err = ctx.GetStub().DelState(balanceKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete balance key %s: %v", balanceKey, err)
+		}
+		return nil
+	}
+
+	err = ctx.GetStub().PutState(balanceKey, []byte(strconv.Itoa(copies)))
+	if err != nil {
+		return fmt.Errorf("failed to put balance key %s: %v", balanceKey, err)
+	}
+
+	return nil
+}
+
+// transferCopies moves copies of an edition from the balance of one holder to another
+// Dependant functions include TransferFrom and CompleteRecovery
+func transferCopies(ctx contractapi.TransactionContextInterface, tokenID string, from string, to string, copies int) error {
+
+	// Both balances are read before either is written, so a move to the same holder would add the copies twice
+	if from == to {
+		return fmt.Errorf("%s cannot transfer copies of edition %s to itself", from, tokenID)
+	}
+
+	fromCopies, err := readCopies(ctx, from, tokenID)
+	if err != nil {
+		return err
+	}
+	if fromCopies < copies {
+		return fmt.Errorf("%s holds %d copies of edition %s, cannot transfer %d", from, fromCopies, tokenID, copies)
+	}
+
+	toCopies, err := readCopies(ctx, to, tokenID)
+	if err != nil {
+		return err
+	}
+
+	err = putCopies(ctx, from, tokenID, fromCopies-copies)
+	if err != nil {
+		return err
+	}
+	err = putCopies(ctx, to, tokenID, toCopies+copies)
+	if err != nil {
+		return err
+	}
+
+	// Record when the token changed hands for the cooldown rule
+	return recordTransferTime(ctx, tokenID)
+}
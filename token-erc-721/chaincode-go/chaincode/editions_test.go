@@ -0,0 +1,133 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMintEdition(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.MintEdition(minterCtx, "201", "ipfs://edition-201", 0)
+	require.EqualError(t, err, "supply must be a positive integer")
+
+	nft, err := tokenContract.MintEdition(minterCtx, "201", "ipfs://edition-201", 5)
+	require.NoError(t, err)
+	require.Equal(t, 5, nft.Supply)
+
+	copies, err := tokenContract.BalanceOfEdition(minterCtx, minter, "201")
+	require.NoError(t, err)
+	require.Equal(t, 5, copies)
+
+	balance, err := tokenContract.BalanceOf(minterCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 5, balance)
+
+	_, err = tokenContract.OwnerOf(minterCtx, "201")
+	require.EqualError(t, err, "token 201 is an edition of 5 copies without a single owner, use OwnersOfEdition")
+
+	_, err = tokenContract.Approve(minterCtx, operator, "201")
+	require.EqualError(t, err, "token 201 is an edition, approve an operator with SetApprovalForAll instead")
+
+	// A supply of 1 is a regular single token
+	nft, err = tokenContract.MintEdition(minterCtx, "202", "", 1)
+	require.NoError(t, err)
+	require.Equal(t, 0, nft.Supply)
+	owner, err := tokenContract.OwnerOf(minterCtx, "202")
+	require.NoError(t, err)
+	require.Equal(t, minter, owner)
+	copies, err = tokenContract.BalanceOfEdition(minterCtx, minter, "202")
+	require.NoError(t, err)
+	require.Equal(t, 1, copies)
+}
+
+func TestTransferEditionCopies(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	_, err := tokenContract.MintEdition(minterCtx, "201", "ipfs://edition-201", 5)
+	require.NoError(t, err)
+
+	// Each transfer moves one copy
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "201")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "201")
+	require.NoError(t, err)
+
+	copies, err := tokenContract.BalanceOfEdition(minterCtx, minter, "201")
+	require.NoError(t, err)
+	require.Equal(t, 3, copies)
+	copies, err = tokenContract.BalanceOfEdition(minterCtx, recipient, "201")
+	require.NoError(t, err)
+	require.Equal(t, 2, copies)
+
+	// A holder can only move their own copies
+	_, err = tokenContract.TransferFrom(recipientCtx, minter, recipient, "201")
+	require.EqualError(t, err, "the sender is not allowed to transfer the non-fungible token")
+	_, err = tokenContract.TransferFrom(recipientCtx, operator, recipient, "201")
+	require.EqualError(t, err, "the sender is not allowed to transfer the non-fungible token")
+	_, err = tokenContract.TransferFrom(prepMocks(stub, org2MSP, operator), operator, recipient, "201")
+	require.EqualError(t, err, "the from holds no copy of edition 201")
+
+	_, err = tokenContract.TransferFrom(recipientCtx, recipient, operator, "201")
+	require.NoError(t, err)
+	copies, err = tokenContract.BalanceOfEdition(minterCtx, recipient, "201")
+	require.NoError(t, err)
+	require.Equal(t, 1, copies)
+
+	// A transfer to the holder itself leaves the copies and the supply unchanged
+	_, err = tokenContract.TransferFrom(minterCtx, minter, minter, "201")
+	require.EqualError(t, err, "minter cannot transfer copies of edition 201 to itself")
+	copies, err = tokenContract.BalanceOfEdition(minterCtx, minter, "201")
+	require.NoError(t, err)
+	require.Equal(t, 3, copies)
+	owners, err := tokenContract.OwnersOfEdition(minterCtx, "201")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{minter: 3, recipient: 1, operator: 1}, owners)
+	supply, err := tokenContract.TotalSupply(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, 1, supply)
+
+	// An edition can only be burned by the holder of every copy
+	_, err = tokenContract.Burn(minterCtx, "201")
+	require.EqualError(t, err, "edition 201 can only be burned by the holder of all 5 copies")
+}
+
+func TestOwnersOfEdition(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.MintEdition(minterCtx, "201", "ipfs://edition-201", 5)
+	require.NoError(t, err)
+	_, err = tokenContract.MintEdition(minterCtx, "202", "ipfs://edition-202", 3)
+	require.NoError(t, err)
+
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "201")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "201")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "201")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "202")
+	require.NoError(t, err)
+
+	owners, err := tokenContract.OwnersOfEdition(minterCtx, "201")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{minter: 2, recipient: 2, operator: 1}, owners)
+
+	// A single token has its owner as the sole holder
+	owners, err = tokenContract.OwnersOfEdition(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{minter: 1}, owners)
+
+	_, err = tokenContract.OwnersOfEdition(minterCtx, "999")
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
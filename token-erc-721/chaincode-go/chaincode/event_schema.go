@@ -0,0 +1,113 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+)
+
+// Define objectType names for prefix
+const eventDisabledPrefix = "eventDisabled"
+
+// EventSchema describes an event the contract emits and the fields of its JSON payload
+type EventSchema struct {
+	Name   string             `json:"name"`
+	Fields []EventFieldSchema `json:"fields"`
+}
+
+// EventFieldSchema describes one field of an event payload
+// Type is one of string, integer, boolean or array. Optional fields are left out of the payload when empty.
+type EventFieldSchema struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional"`
+}
+
+// emittedEvents lists every event name with the payload type it is emitted with
+// The schema is derived from the payload types, so it cannot drift from what is emitted
+var emittedEvents = []struct {
+	name    string
+	payload interface{}
+}{
+	{"Transfer", events.TransferEvent{}},
+	{"Approval", events.ApprovalEvent{}},
+	{"ApprovalForAll", events.ApprovalForAllEvent{}},
+	{"ApprovalForAllBatch", events.ApprovalForAllBatchEvent{}},
+	{"Sale", events.SaleEvent{}},
+	{"MetadataUpdate", events.MetadataUpdateEvent{}},
+	{"Reissued", events.ReissuedEvent{}},
+	{"Airdrop", events.AirdropEvent{}},
+	{"RecoveryInitiated", events.RecoveryEvent{}},
+	{"RecoveryCancelled", events.RecoveryEvent{}},
+	{"Recovery", events.RecoveryEvent{}},
+}
+
+// GetEventSchema returns a JSON description of every event the contract emits and the fields of its payload
+// Off-chain developers can use it to generate typed event listeners
+func (s *SmartContract) GetEventSchema(ctx contractapi.TransactionContextInterface) (string, error) {
+	schemas := make([]EventSchema, 0, len(emittedEvents))
+	for _, event := range emittedEvents {
+		schema := EventSchema{Name: event.name, Fields: []EventFieldSchema{}}
+
+		payloadType := reflect.TypeOf(event.payload)
+		for i := 0; i < payloadType.NumField(); i++ {
+			field := payloadType.Field(i)
+			tag := strings.Split(field.Tag.Get("json"), ",")
+			schema.Fields = append(schema.Fields, EventFieldSchema{
+				Name:     tag[0],
+				Type:     schemaType(field.Type.Kind()),
+				Optional: len(tag) > 1 && tag[1] == "omitempty",
+			})
+		}
+		schemas = append(schemas, schema)
+	}
+
+	schemaJSON, err := json.Marshal(schemas)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	return string(schemaJSON), nil
+}
+
+// SetEventEmission turns the emission of one event on or off, every event is emitted by default
+// Deployments that do not consume an event can turn it off to save payload space. The state changes
+// of the functions emitting it are unaffected.
+func (s *SmartContract) SetEventEmission(ctx contractapi.TransactionContextInterface, eventName string, enabled bool) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := false
+	for _, event := range emittedEvents {
+		if event.name == eventName {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown event %s", eventName)
+	}
+
+	return setFlag(ctx, eventDisabledPrefix, eventName, !enabled)
+}
+
+// Helper Functions
+
+func schemaType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice:
+		return "array"
+	}
+
+	return "string"
+}
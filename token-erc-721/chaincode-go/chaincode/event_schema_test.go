@@ -0,0 +1,98 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEventSchema(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	schemaJSON, err := tokenContract.GetEventSchema(minterCtx)
+	require.NoError(t, err)
+
+	var schemas []chaincode.EventSchema
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), &schemas))
+
+	fields := map[string][]string{}
+	for _, schema := range schemas {
+		for _, field := range schema.Fields {
+			fields[schema.Name] = append(fields[schema.Name], field.Name+":"+field.Type)
+		}
+	}
+	require.Equal(t, map[string][]string{
+		"Transfer":            {"from:string", "to:string", "tokenId:string"},
+		"Approval":            {"owner:string", "approved:string", "tokenId:string"},
+		"ApprovalForAll":      {"owner:string", "operator:string", "approved:boolean"},
+		"ApprovalForAllBatch": {"owner:string", "operators:array", "approved:boolean"},
+		"Sale":                {"from:string", "to:string", "tokenId:string", "salePrice:integer"},
+		"MetadataUpdate":      {"tokenIds:array"},
+		"Reissued":            {"tokenId:string", "previousURI:string", "tokenURI:string"},
+		"Airdrop":             {"from:string", "recipients:array", "tokenIds:array"},
+		"RecoveryInitiated":   {"owner:string", "recovery:string", "tokenIds:array"},
+		"RecoveryCancelled":   {"owner:string", "recovery:string", "tokenIds:array"},
+		"Recovery":            {"owner:string", "recovery:string", "tokenIds:array"},
+	}, fields)
+
+	// The schema matches the payload that is actually emitted
+	drainEvents(stub)
+	_, err = tokenContract.SetApprovalForAll(minterCtx, operator, true)
+	require.NoError(t, err)
+	emitted := drainEvents(stub)
+	require.Len(t, emitted, 1)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(emitted[0].Payload, &payload))
+	for _, schema := range schemas {
+		if schema.Name != emitted[0].EventName {
+			continue
+		}
+		require.Len(t, payload, len(schema.Fields))
+		for _, field := range schema.Fields {
+			require.Contains(t, payload, field.Name)
+		}
+	}
+}
+
+func TestSetEventEmission(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetEventEmission(prepMocks(stub, org2MSP, recipient), "Transfer", false)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+	err = tokenContract.SetEventEmission(minterCtx, "Teleport", false)
+	require.EqualError(t, err, "unknown event Teleport")
+
+	err = tokenContract.SetEventEmission(minterCtx, "Transfer", false)
+	require.NoError(t, err)
+
+	// The transfer takes place without an event
+	drainEvents(stub)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	require.Empty(t, drainEvents(stub))
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, recipient, owner)
+
+	// Other events are still emitted
+	_, err = tokenContract.Approve(minterCtx, operator, "102")
+	require.NoError(t, err)
+	emitted := drainEvents(stub)
+	require.Len(t, emitted, 1)
+	require.Equal(t, "Approval", emitted[0].EventName)
+
+	err = tokenContract.SetEventEmission(minterCtx, "Transfer", true)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "102")
+	require.NoError(t, err)
+	emitted = drainEvents(stub)
+	require.Len(t, emitted, 1)
+	require.Equal(t, "Transfer", emitted[0].EventName)
+}
@@ -0,0 +1,112 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// feature is an optional feature of the contract, reported by GetSupportedFeatures while it is enabled
+// enabled reads the current configuration, a nil enabled means the feature is always available
+type feature struct {
+	name    string
+	enabled func(ctx contractapi.TransactionContextInterface) (bool, error)
+}
+
+// supportedFeatures is the registry of optional features, in the order they are reported
+var supportedFeatures = []feature{
+	{"metadata", nil},
+	{"enumerable", nil},
+	{"editions", nil},
+	{"approvalExpiry", nil},
+	{"burnPolicy", nil},
+	{"transferRules", anyTransferRuleEnabled},
+	{"transferCallbacks", isOptionSet(transferCallbackKey)},
+	{"transferLimits", nil},
+	{"launchGate", isOptionSet(transfersEnabledAtKey)},
+	{"mintRateLimit", isMintRateLimited},
+	{"restrictions", nil},
+	{"offers", nil},
+	{"sales", nil},
+	{"airdrop", nil},
+	{"recovery", nil},
+	{"profiles", nil},
+	{"handles", nil},
+	{"localizedURIs", nil},
+	{"frozenURIs", nil},
+	{"privateMetadata", nil},
+	{"signedMetadata", isOptionSet(metadataIssuerKey)},
+	{"hashedAccounts", isOptionSet(hashedAccountsKey)},
+	{"modificationTracking", isModificationTrackingEnabled},
+	{"eventToggles", nil},
+	{"strictMode", isOptionSet(strictModeKey)},
+}
+
+// GetSupportedFeatures returns the names of the optional features the running chaincode has enabled
+// Features that are always available are always reported, configurable ones only while they are turned on,
+// for example signedMetadata once a metadata issuer is set.
+// Pause, royalties, soulbound tokens and escrow are not part of this contract and are not reported.
+func (s *SmartContract) GetSupportedFeatures(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	features := make([]string, 0, len(supportedFeatures))
+	for _, f := range supportedFeatures {
+		if f.enabled != nil {
+			enabled, err := f.enabled(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if !enabled {
+				continue
+			}
+		}
+
+		features = append(features, f.name)
+	}
+
+	return features, nil
+}
+
+// Helper Functions
+
+// isOptionSet returns a check that a feature is enabled while the option stored under key is set
+func isOptionSet(key string) func(ctx contractapi.TransactionContextInterface) (bool, error) {
+	return func(ctx contractapi.TransactionContextInterface) (bool, error) {
+		valueBytes, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s: %v", key, err)
+		}
+
+		return len(valueBytes) > 0, nil
+	}
+}
+
+func anyTransferRuleEnabled(ctx contractapi.TransactionContextInterface) (bool, error) {
+	for _, rule := range transferRules {
+		enabled, err := isTransferRuleEnabled(ctx, rule.name)
+		if err != nil {
+			return false, err
+		}
+		if enabled {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func isMintRateLimited(ctx contractapi.TransactionContextInterface) (bool, error) {
+	max, err := readIntOption(ctx, mintRateMaxKey)
+	if err != nil {
+		return false, err
+	}
+
+	return max > 0, nil
+}
+
+func isModificationTrackingEnabled(ctx contractapi.TransactionContextInterface) (bool, error) {
+	disabled, err := isOptionSet(modificationTrackingDisabledKey)(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return !disabled, nil
+}
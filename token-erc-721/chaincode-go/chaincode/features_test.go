@@ -0,0 +1,99 @@
+package chaincode_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSupportedFeatures(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+	ctx := prepMocks(stub, org2MSP, recipient)
+
+	// A fresh chaincode reports the features that are always available and modification tracking, which is on by default
+	features, err := tokenContract.GetSupportedFeatures(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"metadata",
+		"enumerable",
+		"editions",
+		"approvalExpiry",
+		"burnPolicy",
+		"transferLimits",
+		"restrictions",
+		"offers",
+		"sales",
+		"airdrop",
+		"recovery",
+		"profiles",
+		"handles",
+		"localizedURIs",
+		"frozenURIs",
+		"privateMetadata",
+		"modificationTracking",
+		"eventToggles",
+	}, features)
+
+	// Features outside this contract are not claimed
+	require.NotContains(t, features, "pause")
+	require.NotContains(t, features, "royalty")
+	require.NotContains(t, features, "soulbound")
+
+	// Turning configurable features on and off changes the list
+	require.NoError(t, tokenContract.SetModificationTracking(adminCtx, false))
+	require.NoError(t, tokenContract.SetHashedAccounts(adminCtx, true))
+	features, err = tokenContract.GetSupportedFeatures(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, features, "modificationTracking")
+	require.Contains(t, features, "hashedAccounts")
+
+	issuer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, tokenContract.SetMetadataIssuer(adminCtx, issuerPEM(t, issuer)))
+	require.NoError(t, tokenContract.SetMintRateLimit(adminCtx, 10, 3600))
+	require.NoError(t, tokenContract.SetTransfersEnabledAt(adminCtx, 2000))
+	require.NoError(t, tokenContract.EnableTransferRule(adminCtx, "cooldown", true))
+	require.NoError(t, tokenContract.SetTransferCallback(adminCtx, "listener", "OnTransfer", true))
+	features, err = tokenContract.GetSupportedFeatures(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"metadata",
+		"enumerable",
+		"editions",
+		"approvalExpiry",
+		"burnPolicy",
+		"transferRules",
+		"transferCallbacks",
+		"transferLimits",
+		"launchGate",
+		"mintRateLimit",
+		"restrictions",
+		"offers",
+		"sales",
+		"airdrop",
+		"recovery",
+		"profiles",
+		"handles",
+		"localizedURIs",
+		"frozenURIs",
+		"privateMetadata",
+		"signedMetadata",
+		"hashedAccounts",
+		"eventToggles",
+	}, features)
+
+	require.NoError(t, tokenContract.SetMintRateLimit(adminCtx, 0, 0))
+	require.NoError(t, tokenContract.EnableTransferRule(adminCtx, "cooldown", false))
+	require.NoError(t, tokenContract.SetTransfersEnabledAt(adminCtx, 0))
+	features, err = tokenContract.GetSupportedFeatures(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, features, "mintRateLimit")
+	require.NotContains(t, features, "transferRules")
+	require.NotContains(t, features, "launchGate")
+}
@@ -0,0 +1,105 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const handlePrefix = "handle"
+const accountHandlePrefix = "accountHandle"
+
+// ClaimHandle binds a human-readable handle, such as an email address, to the account of the calling client
+// A handle belongs to one account and an account holds one handle, neither can be claimed twice.
+func (s *SmartContract) ClaimHandle(ctx contractapi.TransactionContextInterface, handle string) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	if handle == "" {
+		return fmt.Errorf("handle must not be empty")
+	}
+
+	account, err := readHandleEntry(ctx, handlePrefix, handle)
+	if err != nil {
+		return err
+	}
+	if account != "" {
+		return fmt.Errorf("handle %s is already claimed", handle)
+	}
+	claimed, err := readHandleEntry(ctx, accountHandlePrefix, sender)
+	if err != nil {
+		return err
+	}
+	if claimed != "" {
+		return fmt.Errorf("account %s already holds handle %s", sender, claimed)
+	}
+
+	err = putHandleEntry(ctx, handlePrefix, handle, sender)
+	if err != nil {
+		return err
+	}
+
+	return putHandleEntry(ctx, accountHandlePrefix, sender, handle)
+}
+
+// ResolveHandle returns the account a handle is bound to
+func (s *SmartContract) ResolveHandle(ctx contractapi.TransactionContextInterface, handle string) (string, error) {
+	return resolveHandle(ctx, handle)
+}
+
+// TransferToHandle transfers a non-fungible token to the account a handle is bound to, like TransferFrom
+// This function triggers a Transfer event
+func (s *SmartContract) TransferToHandle(ctx contractapi.TransactionContextInterface, from string, handle string, tokenID string) (bool, error) {
+	to, err := resolveHandle(ctx, handle)
+	if err != nil {
+		return false, err
+	}
+
+	return s.TransferFrom(ctx, from, to, tokenID)
+}
+
+// Helper Functions
+
+func resolveHandle(ctx contractapi.TransactionContextInterface, handle string) (string, error) {
+	account, err := readHandleEntry(ctx, handlePrefix, handle)
+	if err != nil {
+		return "", err
+	}
+	if account == "" {
+		return "", fmt.Errorf("handle %s is not claimed", handle)
+	}
+
+	return account, nil
+}
+
+// readHandleEntry returns the value stored under a handle or an account, empty if none is stored
+func readHandleEntry(ctx contractapi.TransactionContextInterface, prefix string, name string) (string, error) {
+	entryKey, err := ctx.GetStub().CreateCompositeKey(prefix, []string{name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", prefix, err)
+	}
+	entryBytes, err := ctx.GetStub().GetState(entryKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s entry for %s: %v", prefix, name, err)
+	}
+
+	return string(entryBytes), nil
+}
+
+func putHandleEntry(ctx contractapi.TransactionContextInterface, prefix string, name string, value string) error {
+	entryKey, err := ctx.GetStub().CreateCompositeKey(prefix, []string{name})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", prefix, err)
+	}
+	err = ctx.GetStub().PutState(entryKey, []byte(value))
+	if err != nil {
+		return fmt.Errorf("failed to put %s entry for %s: %v", prefix, name, err)
+	}
+
+	return nil
+}
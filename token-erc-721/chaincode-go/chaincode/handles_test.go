@@ -0,0 +1,41 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandles(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	err := tokenContract.ClaimHandle(recipientCtx, "")
+	require.EqualError(t, err, "handle must not be empty")
+	err = tokenContract.ClaimHandle(recipientCtx, "alice@example.com")
+	require.NoError(t, err)
+
+	account, err := tokenContract.ResolveHandle(minterCtx, "alice@example.com")
+	require.NoError(t, err)
+	require.Equal(t, recipient, account)
+	_, err = tokenContract.ResolveHandle(minterCtx, "bob@example.com")
+	require.EqualError(t, err, "handle bob@example.com is not claimed")
+
+	// Handles and accounts are bound one to one
+	err = tokenContract.ClaimHandle(minterCtx, "alice@example.com")
+	require.EqualError(t, err, "handle alice@example.com is already claimed")
+	err = tokenContract.ClaimHandle(recipientCtx, "alice2@example.com")
+	require.EqualError(t, err, "account "+recipient+" already holds handle alice@example.com")
+
+	_, err = tokenContract.TransferToHandle(minterCtx, minter, "bob@example.com", "101")
+	require.EqualError(t, err, "handle bob@example.com is not claimed")
+	_, err = tokenContract.TransferToHandle(minterCtx, minter, "alice@example.com", "101")
+	require.NoError(t, err)
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, recipient, owner)
+}
@@ -0,0 +1,152 @@
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const heldSincePrefix = "heldSince"
+
+// GetHoldingDuration returns the number of seconds since the current owner acquired a non-fungible token
+// Editions have no single owner, so they have no holding duration.
+func (s *SmartContract) GetHoldingDuration(ctx contractapi.TransactionContextInterface, tokenID string) (int64, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return 0, err
+	}
+	if nft.isEdition() {
+		return 0, fmt.Errorf("token %s is an edition of %d copies without a single owner", tokenID, nft.Supply)
+	}
+
+	heldSinceKey, err := ctx.GetStub().CreateCompositeKey(heldSincePrefix, []string{tokenID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", heldSincePrefix, err)
+	}
+	heldSinceBytes, err := ctx.GetStub().GetState(heldSinceKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read holding time of token %s: %v", tokenID, err)
+	}
+	if len(heldSinceBytes) == 0 {
+		return 0, fmt.Errorf("no holding time is recorded for token %s", tokenID)
+	}
+
+	heldSince, _ := strconv.ParseInt(string(heldSinceBytes), 10, 64) // Error handling not needed since FormatInt() was used when setting the time, guaranteeing it was an integer.
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return now - heldSince, nil
+}
+
+// HolderBalance is the balance of one account, counting every copy held of editions
+type HolderBalance struct {
+	Account string `json:"account"`
+	Balance int    `json:"balance"`
+}
+
+// GetHolderCount returns the number of distinct accounts holding at least one token
+// Every balance key of the collection is visited, so the cost grows with the number of tokens held.
+func (s *SmartContract) GetHolderCount(ctx contractapi.TransactionContextInterface) (int, error) {
+	balances, err := holderBalances(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(balances), nil
+}
+
+// GetTopHolders returns the n accounts with the largest balances, largest first
+// Accounts with equal balances are ordered by account ID. Like GetHolderCount, every balance key
+// of the collection is visited, so for large collections this is better answered off-chain.
+func (s *SmartContract) GetTopHolders(ctx contractapi.TransactionContextInterface, n int) ([]HolderBalance, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of holders must be a positive integer")
+	}
+
+	balances, err := holderBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	holders := []HolderBalance{}
+	for account, balance := range balances {
+		holders = append(holders, HolderBalance{Account: account, Balance: balance})
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if holders[i].Balance != holders[j].Balance {
+			return holders[i].Balance > holders[j].Balance
+		}
+		return holders[i].Account < holders[j].Account
+	})
+	if len(holders) > n {
+		holders = holders[:n]
+	}
+
+	return holders, nil
+}
+
+// Helper Functions
+
+// holderBalances returns the balance of every account holding at least one token
+func holderBalances(ctx contractapi.TransactionContextInterface) (map[string]int, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(balancePrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance keys: %v", err)
+	}
+	defer iterator.Close()
+
+	balances := map[string]int{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read balance key: %v", err)
+		}
+
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split the composite key %s: %v", queryResponse.Key, err)
+		}
+		balances[compositeKeyParts[0]] += parseCopies(queryResponse.Value)
+	}
+
+	return balances, nil
+}
+
+// recordHeldSince stores when the current owner of a token acquired it
+// Dependant functions include mintHelper and transferHelper
+func recordHeldSince(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	heldSinceKey, err := ctx.GetStub().CreateCompositeKey(heldSincePrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", heldSincePrefix, err)
+	}
+	err = ctx.GetStub().PutState(heldSinceKey, []byte(strconv.FormatInt(now, 10)))
+	if err != nil {
+		return fmt.Errorf("failed to put holding time of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
+
+func deleteHeldSince(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	heldSinceKey, err := ctx.GetStub().CreateCompositeKey(heldSincePrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", heldSincePrefix, err)
+	}
+	err = ctx.GetStub().DelState(heldSinceKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete holding time of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
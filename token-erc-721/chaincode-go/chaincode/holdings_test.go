@@ -0,0 +1,74 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHoldingDuration(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	setTxTime(stub, 1000)
+	mintTokens(t, stub, "101")
+
+	// The duration grows with time
+	setTxTime(stub, 1500)
+	duration, err := tokenContract.GetHoldingDuration(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, int64(500), duration)
+
+	// and resets when the token changes hands
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	duration, err = tokenContract.GetHoldingDuration(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), duration)
+
+	setTxTime(stub, 1600)
+	duration, err = tokenContract.GetHoldingDuration(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, int64(100), duration)
+
+	_, err = tokenContract.MintEdition(minterCtx, "201", "", 10)
+	require.NoError(t, err)
+	_, err = tokenContract.GetHoldingDuration(minterCtx, "201")
+	require.EqualError(t, err, "token 201 is an edition of 10 copies without a single owner")
+}
+
+func TestHolders(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	count, err := tokenContract.GetHolderCount(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	// The minter keeps 3 tokens, the recipient gets 2 and the operator 1
+	mintTokens(t, stub, "101", "102", "103", "104", "105", "106")
+	for _, tokenID := range []string{"104", "105"} {
+		_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, tokenID)
+		require.NoError(t, err)
+	}
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "106")
+	require.NoError(t, err)
+
+	count, err = tokenContract.GetHolderCount(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	holders, err := tokenContract.GetTopHolders(minterCtx, 2)
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.HolderBalance{{Account: minter, Balance: 3}, {Account: recipient, Balance: 2}}, holders)
+
+	holders, err = tokenContract.GetTopHolders(minterCtx, 10)
+	require.NoError(t, err)
+	require.Len(t, holders, 3)
+
+	_, err = tokenContract.GetTopHolders(minterCtx, 0)
+	require.EqualError(t, err, "number of holders must be a positive integer")
+}
@@ -0,0 +1,96 @@
+package chaincode
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ReconcileBalances removes stale balance keys of an owner and returns how many were removed
+// A balance key is stale when its token no longer exists, or when the token is a single token owned by somebody else.
+// Such keys can be left behind by transfers that were only partially applied by earlier versions of this contract.
+func (s *SmartContract) ReconcileBalances(ctx contractapi.TransactionContextInterface, owner string) (int, error) {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tokenIDs, err := ownedTokenIDs(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, tokenID := range tokenIDs {
+		exists, err := nftExists(ctx, tokenID)
+		if err != nil {
+			return 0, err
+		}
+
+		stale := !exists
+		if exists {
+			nft, err := readNFT(ctx, tokenID)
+			if err != nil {
+				return 0, err
+			}
+
+			// The copies of an edition are only recorded in balance keys, so any holder's key is valid
+			stale = !nft.isEdition() && nft.Owner != owner
+		}
+		if !stale {
+			continue
+		}
+
+		balanceKey, err := ctx.GetStub().CreateCompositeKey(balancePrefix, []string{owner, tokenID})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", balancePrefix, err)
+		}
+		err = ctx.GetStub().DelState(balanceKey)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete balance key %s: %v", balanceKey, err)
+		}
+		removed++
+	}
+
+	log.Printf("removed %d stale balance keys of %s", removed, owner)
+
+	return removed, nil
+}
+
+// RecomputeTotalSupply counts the live tokens and corrects the burn counter so that GetTotalMinted minus
+// GetTotalBurned matches them again, returning the corrected total supply.
+// TotalSupply itself counts the nft records and cannot drift, unlike the counters it is expected to agree with.
+// The mint counter is kept, since it also gates SetHashedAccounts, and is only raised if it is below the count.
+// Every nft record is visited, so the cost grows with the size of the collection.
+func (s *SmartContract) RecomputeTotalSupply(ctx contractapi.TransactionContextInterface) (int, error) {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	supply, err := s.TotalSupply(ctx)
+	if err != nil {
+		return 0, err
+	}
+	minted, err := readIntOption(ctx, totalMintedKey)
+	if err != nil {
+		return 0, err
+	}
+	if minted < supply {
+		minted = supply
+		err = ctx.GetStub().PutState(totalMintedKey, []byte(strconv.Itoa(minted)))
+		if err != nil {
+			return 0, fmt.Errorf("failed to put %s: %v", totalMintedKey, err)
+		}
+	}
+	err = ctx.GetStub().PutState(totalBurnedKey, []byte(strconv.Itoa(minted-supply)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to put %s: %v", totalBurnedKey, err)
+	}
+
+	log.Printf("total supply recomputed as %d of %d minted", supply, minted)
+
+	return supply, nil
+}
@@ -0,0 +1,96 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileBalances(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.MintEdition(adminCtx, "201", "", 3)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "201")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "102")
+	require.NoError(t, err)
+
+	// Construct stale keys: one for a token that does not exist, one for a token owned by the minter
+	staleKey, err := stub.CreateCompositeKey("balance", []string{recipient, "999"})
+	require.NoError(t, err)
+	require.NoError(t, stub.PutState(staleKey, []byte{0x00}))
+	staleKey, err = stub.CreateCompositeKey("balance", []string{recipient, "101"})
+	require.NoError(t, err)
+	require.NoError(t, stub.PutState(staleKey, []byte{0x00}))
+
+	balance, err := tokenContract.BalanceOf(adminCtx, recipient)
+	require.NoError(t, err)
+	require.Equal(t, 4, balance)
+
+	_, err = tokenContract.ReconcileBalances(prepMocks(stub, org2MSP, recipient), recipient)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	removed, err := tokenContract.ReconcileBalances(adminCtx, recipient)
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+
+	// The owned token and the edition copy remain
+	balance, err = tokenContract.BalanceOf(adminCtx, recipient)
+	require.NoError(t, err)
+	require.Equal(t, 2, balance)
+
+	removed, err = tokenContract.ReconcileBalances(adminCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 0, removed)
+}
+
+func TestRecomputeTotalSupply(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.Burn(adminCtx, "103")
+	require.NoError(t, err)
+
+	// Corrupt the burn counter so that it no longer agrees with the live tokens
+	require.NoError(t, stub.PutState("totalBurned", []byte("5")))
+	report, err := tokenContract.CheckTokenConsistency(adminCtx, "101")
+	require.NoError(t, err)
+	require.False(t, report.Consistent)
+
+	_, err = tokenContract.RecomputeTotalSupply(prepMocks(stub, org2MSP, recipient))
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	supply, err := tokenContract.RecomputeTotalSupply(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, 2, supply)
+
+	minted, err := tokenContract.GetTotalMinted(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, 3, minted)
+	burned, err := tokenContract.GetTotalBurned(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, 1, burned)
+
+	report, err = tokenContract.CheckTokenConsistency(adminCtx, "101")
+	require.NoError(t, err)
+	require.True(t, report.Consistent)
+
+	// A mint counter below the live tokens is raised to match them
+	require.NoError(t, stub.PutState("totalMinted", []byte("1")))
+	supply, err = tokenContract.RecomputeTotalSupply(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, 2, supply)
+	minted, err = tokenContract.GetTotalMinted(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, 2, minted)
+	burned, err = tokenContract.GetTotalBurned(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, 0, burned)
+}
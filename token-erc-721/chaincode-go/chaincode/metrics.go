@@ -0,0 +1,78 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ContractMetrics summarizes the state of the collection for operators
+// Total is the number of live tokens, Minted and Burned the counters behind GetTotalMinted and GetTotalBurned.
+// Restricted counts live tokens restricted to an organization, OperatorApprovals the granted approvals for all.
+type ContractMetrics struct {
+	Total             int `json:"total"`
+	Minted            int `json:"minted"`
+	Burned            int `json:"burned"`
+	Restricted        int `json:"restricted"`
+	OperatorApprovals int `json:"operatorApprovals"`
+}
+
+// GetContractMetrics returns the counts of the collection in one call
+// Minted and Burned come from the maintained counters. The other counts scan the nft records, the
+// restrictions and the approvals for all, so the cost grows with the size of the collection.
+func (s *SmartContract) GetContractMetrics(ctx contractapi.TransactionContextInterface) (*ContractMetrics, error) {
+	metrics := &ContractMetrics{}
+
+	var err error
+	metrics.Total, err = s.TotalSupply(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metrics.Minted, err = readIntOption(ctx, totalMintedKey)
+	if err != nil {
+		return nil, err
+	}
+	metrics.Burned, err = readIntOption(ctx, totalBurnedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Burn removes the restriction of a token, so every restriction belongs to a live token
+	restrictions, err := ctx.GetStub().GetStateByPartialCompositeKey(restrictionPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restriction keys: %v", err)
+	}
+	defer restrictions.Close()
+	for restrictions.HasNext() {
+		_, err := restrictions.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read restriction key: %v", err)
+		}
+		metrics.Restricted++
+	}
+
+	// Revoking an operator keeps its approval record with Approved false
+	approvals, err := ctx.GetStub().GetStateByPartialCompositeKey(approvalPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval keys: %v", err)
+	}
+	defer approvals.Close()
+	for approvals.HasNext() {
+		queryResponse, err := approvals.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read approval key: %v", err)
+		}
+
+		var approval Approval
+		err = json.Unmarshal(queryResponse.Value, &approval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal approval %s: %v", queryResponse.Key, err)
+		}
+		if approval.Approved {
+			metrics.OperatorApprovals++
+		}
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,41 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetContractMetrics(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	metrics, err := tokenContract.GetContractMetrics(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ContractMetrics{}, metrics)
+
+	mintTokens(t, stub, "101", "102", "103")
+	for _, tokenID := range []string{"201", "202"} {
+		_, err = tokenContract.MintRestricted(minterCtx, tokenID, "", org1MSP)
+		require.NoError(t, err)
+	}
+	_, err = tokenContract.Burn(minterCtx, "103")
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(minterCtx, "202")
+	require.NoError(t, err)
+
+	_, err = tokenContract.SetApprovalForAll(minterCtx, operator, true)
+	require.NoError(t, err)
+	_, err = tokenContract.SetApprovalForAll(minterCtx, recipient, true)
+	require.NoError(t, err)
+	_, err = tokenContract.SetApprovalForAll(prepMocks(stub, org2MSP, recipient), operator, true)
+	require.NoError(t, err)
+	_, err = tokenContract.SetApprovalForAll(minterCtx, recipient, false)
+	require.NoError(t, err)
+
+	metrics, err = tokenContract.GetContractMetrics(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ContractMetrics{Total: 3, Minted: 5, Burned: 2, Restricted: 1, OperatorApprovals: 2}, metrics)
+}
@@ -0,0 +1,103 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define key names for options
+const mintRateMaxKey = "mintRateMax"
+const mintRateWindowKey = "mintRateWindow"
+const mintWindowStartKey = "mintWindowStart"
+const mintWindowCountKey = "mintWindowCount"
+
+// SetMintRateLimit allows at most max mints in every window of windowSeconds, counted across all minters
+// A max of zero removes the limit
+func (s *SmartContract) SetMintRateLimit(ctx contractapi.TransactionContextInterface, max int, windowSeconds int) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if max < 0 {
+		return fmt.Errorf("mint rate limit cannot be negative")
+	}
+	if max > 0 && windowSeconds <= 0 {
+		return fmt.Errorf("mint rate window must be a positive number of seconds")
+	}
+
+	err = ctx.GetStub().PutState(mintRateMaxKey, []byte(strconv.Itoa(max)))
+	if err != nil {
+		return fmt.Errorf("failed to set mint rate limit: %v", err)
+	}
+	err = ctx.GetStub().PutState(mintRateWindowKey, []byte(strconv.Itoa(windowSeconds)))
+	if err != nil {
+		return fmt.Errorf("failed to set mint rate window: %v", err)
+	}
+
+	return nil
+}
+
+// Helper Functions
+
+// checkMintRateLimit counts a mint against the current window and rejects it once the window is full
+// The window starts at the first mint after the previous window ended. Without record the mint is only checked.
+func checkMintRateLimit(ctx contractapi.TransactionContextInterface, record bool) error {
+	max, err := readIntOption(ctx, mintRateMaxKey)
+	if err != nil {
+		return err
+	}
+
+	// Without a configured limit there is nothing to enforce
+	if max == 0 {
+		return nil
+	}
+
+	window, err := readIntOption(ctx, mintRateWindowKey)
+	if err != nil {
+		return err
+	}
+	windowStartBytes, err := ctx.GetStub().GetState(mintWindowStartKey)
+	if err != nil {
+		return fmt.Errorf("failed to read mint window start: %v", err)
+	}
+	count, err := readIntOption(ctx, mintWindowCountKey)
+	if err != nil {
+		return err
+	}
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	windowStart, _ := strconv.ParseInt(string(windowStartBytes), 10, 64) // Error handling not needed since FormatInt() was used when setting the time, guaranteeing it was an integer.
+	newWindow := len(windowStartBytes) == 0 || now >= windowStart+int64(window)
+	if newWindow {
+		windowStart = now
+		count = 0
+	}
+
+	if count >= max {
+		return fmt.Errorf("mint rate limit of %d per %d seconds reached until %d", max, window, windowStart+int64(window))
+	}
+	if !record {
+		return nil
+	}
+
+	if newWindow {
+		err = ctx.GetStub().PutState(mintWindowStartKey, []byte(strconv.FormatInt(windowStart, 10)))
+		if err != nil {
+			return fmt.Errorf("failed to put mint window start: %v", err)
+		}
+	}
+
+	err = ctx.GetStub().PutState(mintWindowCountKey, []byte(strconv.Itoa(count+1)))
+	if err != nil {
+		return fmt.Errorf("failed to put mint window count: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetMintRateLimit(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetMintRateLimit(prepMocks(stub, org2MSP, recipient), 2, 60)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	err = tokenContract.SetMintRateLimit(minterCtx, 2, 0)
+	require.EqualError(t, err, "mint rate window must be a positive number of seconds")
+
+	err = tokenContract.SetMintRateLimit(minterCtx, 2, 60)
+	require.NoError(t, err)
+
+	setTxTime(stub, 1000)
+	mintTokens(t, stub, "101")
+	setTxTime(stub, 1059)
+	mintTokens(t, stub, "102")
+
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "103", "")
+	require.EqualError(t, err, "mint rate limit of 2 per 60 seconds reached until 1060")
+
+	// Once the window rolls the count starts over
+	setTxTime(stub, 1060)
+	mintTokens(t, stub, "103", "104")
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "105", "")
+	require.EqualError(t, err, "mint rate limit of 2 per 60 seconds reached until 1120")
+
+	// Removing the limit allows minting again
+	err = tokenContract.SetMintRateLimit(minterCtx, 0, 0)
+	require.NoError(t, err)
+	mintTokens(t, stub, "105")
+}
@@ -0,0 +1,108 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Nft describes a non-fungible token as it is stored in the world state
+// Supply is the number of copies of an edition token, and is omitted for single tokens.
+// The copies of an edition are tracked in the balances of their holders, Owner is the minter of the edition.
+// Creator is the client that minted the token and MintTxID the transaction that minted it, neither ever changes.
+// ContentType is an optional media type hint for the metadata behind TokenURI, such as "image/png".
+// ApprovedUntil is the Unix time in seconds at which Approved lapses, zero if the approval does not expire.
+type Nft struct {
+	TokenID       string `json:"tokenId"`
+	Owner         string `json:"owner"`
+	Creator       string `json:"creator,omitempty" metadata:",optional"`
+	MintTxID      string `json:"mintTxId,omitempty" metadata:",optional"`
+	TokenURI      string `json:"tokenURI"`
+	ContentType   string `json:"contentType,omitempty" metadata:",optional"`
+	Approved      string `json:"approved"`
+	ApprovedUntil int64  `json:"approvedUntil,omitempty" metadata:",optional"`
+	Supply        int    `json:"supply,omitempty" metadata:",optional"`
+}
+
+// String returns a stable, human-readable representation of a token for logs and test failures
+// Every field is printed, in declaration order, so two tokens print alike exactly when they are Equal.
+func (nft Nft) String() string {
+	return fmt.Sprintf("Nft{tokenId: %q, owner: %q, creator: %q, mintTxId: %q, tokenURI: %q, contentType: %q, approved: %q, approvedUntil: %d, supply: %d}",
+		nft.TokenID, nft.Owner, nft.Creator, nft.MintTxID, nft.TokenURI, nft.ContentType, nft.Approved, nft.ApprovedUntil, nft.Supply)
+}
+
+// Equal reports whether two tokens have the same value in every field
+func (nft Nft) Equal(other Nft) bool {
+	return nft == other
+}
+
+// field returns the value of the field with the given JSON name, formatted as a string
+func (nft Nft) field(name string) (string, bool) {
+	switch name {
+	case "tokenId":
+		return nft.TokenID, true
+	case "owner":
+		return nft.Owner, true
+	case "creator":
+		return nft.Creator, true
+	case "mintTxId":
+		return nft.MintTxID, true
+	case "tokenURI":
+		return nft.TokenURI, true
+	case "contentType":
+		return nft.ContentType, true
+	case "approved":
+		return nft.Approved, true
+	case "approvedUntil":
+		return strconv.FormatInt(nft.ApprovedUntil, 10), true
+	case "supply":
+		return strconv.Itoa(nft.Supply), true
+	}
+
+	return "", false
+}
+
+// Approval records whether an operator is allowed to manage all tokens of an owner
+type Approval struct {
+	Owner    string `json:"owner"`
+	Operator string `json:"operator"`
+	Approved bool   `json:"approved"`
+}
+
+// IdentityAttribute is an attribute read from a client's enrollment certificate
+type IdentityAttribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Found bool   `json:"found"`
+}
+
+// MintCheck is the outcome of CanMint, Reason explains why a mint would be rejected
+type MintCheck struct {
+	TokenID string `json:"tokenId"`
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty" metadata:",optional"`
+}
+
+// MintReceipt confirms a mint by MintWithReceipt
+// Sequence is the position of the mint in the modification index, zero while modification tracking is off.
+type MintReceipt struct {
+	TokenID   string `json:"tokenId"`
+	Owner     string `json:"owner"`
+	TxID      string `json:"txId"`
+	Timestamp int64  `json:"timestamp"`
+	Sequence  int    `json:"sequence"`
+}
+
+// BurnReceipt confirms a burn by BurnWithReceipt, Owner is the account the token was burned from
+type BurnReceipt struct {
+	TokenID   string `json:"tokenId"`
+	Owner     string `json:"owner"`
+	TxID      string `json:"txId"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// TokenLookup is the outcome of TryGetToken, Token is nil when Found is false
+type TokenLookup struct {
+	TokenID string `json:"tokenId"`
+	Found   bool   `json:"found"`
+	Token   *Nft   `json:"token,omitempty" metadata:",optional"`
+}
@@ -0,0 +1,42 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNftString(t *testing.T) {
+	nft := chaincode.Nft{TokenID: "101", Owner: minter, TokenURI: "https://example.com/nft/101", Approved: operator, ApprovedUntil: 2000}
+	require.Equal(t, `Nft{tokenId: "101", owner: "minter", creator: "", mintTxId: "", tokenURI: "https://example.com/nft/101", contentType: "", approved: "operator", approvedUntil: 2000, supply: 0}`, nft.String())
+
+	// Quoting keeps values containing separators unambiguous
+	nft = chaincode.Nft{TokenID: "102", Owner: "a, b"}
+	require.Equal(t, `Nft{tokenId: "102", owner: "a, b", creator: "", mintTxId: "", tokenURI: "", contentType: "", approved: "", approvedUntil: 0, supply: 0}`, nft.String())
+}
+
+func TestNftEqual(t *testing.T) {
+	nft := chaincode.Nft{TokenID: "101", Owner: minter, TokenURI: "https://example.com/nft/101", Supply: 5}
+	require.True(t, nft.Equal(nft))
+
+	other := nft
+	require.True(t, nft.Equal(other))
+
+	for _, change := range []func(*chaincode.Nft){
+		func(n *chaincode.Nft) { n.TokenID = "102" },
+		func(n *chaincode.Nft) { n.Owner = recipient },
+		func(n *chaincode.Nft) { n.Creator = recipient },
+		func(n *chaincode.Nft) { n.MintTxID = "tx2" },
+		func(n *chaincode.Nft) { n.TokenURI = "" },
+		func(n *chaincode.Nft) { n.ContentType = "image/png" },
+		func(n *chaincode.Nft) { n.Approved = operator },
+		func(n *chaincode.Nft) { n.ApprovedUntil = 1 },
+		func(n *chaincode.Nft) { n.Supply = 4 },
+	} {
+		other := nft
+		change(&other)
+		require.False(t, nft.Equal(other))
+		require.NotEqual(t, nft.String(), other.String())
+	}
+}
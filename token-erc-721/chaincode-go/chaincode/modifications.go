@@ -0,0 +1,244 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const tokenSequencePrefix = "tokenSequence"
+
+// The modified index uses simple keys of the form modified:sequence:tokenId so that it can be read with a
+// range query starting at a given sequence; composite keys cannot be used with GetStateByRange
+const modifiedIndexPrefix = "modified:"
+
+// Define key names for options
+const modificationSequenceKey = "modificationSequence"
+const modificationTrackingDisabledKey = "modificationTrackingDisabled"
+
+// ModifiedToken describes the latest change of a token
+// Token is nil when the change was a burn
+type ModifiedToken struct {
+	Sequence int    `json:"sequence"`
+	TokenID  string `json:"tokenId"`
+	Burned   bool   `json:"burned"`
+	Token    *Nft   `json:"token,omitempty" metadata:",optional"`
+}
+
+// ModifiedTokensPage is one page of the result of GetTokensModifiedSince
+// Bookmark is empty when there are no further pages
+type ModifiedTokensPage struct {
+	Records  []ModifiedToken `json:"records"`
+	Bookmark string          `json:"bookmark"`
+}
+
+// GetTokensModifiedSince returns the tokens whose latest change has a sequence number greater than sinceSeq,
+// ordered by sequence number. Off-chain indexes can store the highest sequence they have seen and
+// pass it as sinceSeq to fetch only what changed. Pass the returned bookmark to fetch the next page.
+func (s *SmartContract) GetTokensModifiedSince(ctx contractapi.TransactionContextInterface, sinceSeq int, pageSize int, bookmark string) (*ModifiedTokensPage, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
+	}
+
+	// The bookmark is the sequence number of the last record of the previous page
+	if bookmark != "" {
+		bookmarkSeq, err := strconv.Atoi(bookmark)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bookmark %s", bookmark)
+		}
+		if bookmarkSeq > sinceSeq {
+			sinceSeq = bookmarkSeq
+		}
+	}
+
+	// Index keys sort by the zero-padded sequence number, so the range starts right after sinceSeq
+	startKey := modifiedIndexKey(sinceSeq+1, "")
+	endKey := modifiedIndexKey(maxSequence, "")
+
+	iterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get modified tokens: %v", err)
+	}
+	defer iterator.Close()
+
+	page := &ModifiedTokensPage{Records: []ModifiedToken{}}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read modified token: %v", err)
+		}
+
+		// Stop once the page is full; the remaining records belong to the next page
+		if len(page.Records) == pageSize {
+			page.Bookmark = strconv.Itoa(page.Records[pageSize-1].Sequence)
+			break
+		}
+
+		sequence, tokenID := splitModifiedIndexKey(queryResponse.Key)
+
+		record := ModifiedToken{Sequence: sequence, TokenID: tokenID}
+		exists, err := nftExists(ctx, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			record.Token, err = readNFT(ctx, tokenID)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			record.Burned = true
+		}
+		page.Records = append(page.Records, record)
+	}
+
+	return page, nil
+}
+
+// GetTokenSequence returns the sequence number of the latest change of a token
+func (s *SmartContract) GetTokenSequence(ctx contractapi.TransactionContextInterface, tokenID string) (int, error) {
+	return readTokenSequence(ctx, tokenID)
+}
+
+// SetModificationTracking turns the modification index behind GetTokensModifiedSince on or off
+// Tracking is on by default. Every tracked change reads and writes one contract-wide counter, so
+// transactions changing different tokens in the same block conflict under MVCC; deployments that do not
+// need the index can turn it off to let such transactions commit concurrently.
+// Changes made while tracking is off are not reflected in the index.
+func (s *SmartContract) SetModificationTracking(ctx contractapi.TransactionContextInterface, enabled bool) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if enabled {
+		err = ctx.GetStub().DelState(modificationTrackingDisabledKey)
+	} else {
+		err = ctx.GetStub().PutState(modificationTrackingDisabledKey, []byte{0x00})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set modification tracking: %v", err)
+	}
+
+	return nil
+}
+
+// Helper Functions
+
+// maxSequence bounds the range scanned by GetTokensModifiedSince
+const maxSequence = 1<<62 - 1
+
+// touchToken assigns the next contract-wide sequence number to a token and moves it in the modified index
+// Fabric does not let a transaction read its own writes, so this must be called at most once per
+// transaction; functions changing several tokens use touchTokens instead
+func touchToken(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	return touchTokens(ctx, []string{tokenID})
+}
+
+// nextSequence returns the sequence number the next touched token will be assigned, zero while tracking is off
+// It is read before the change, since a transaction does not read its own writes
+func nextSequence(ctx contractapi.TransactionContextInterface) (int, error) {
+	disabledBytes, err := ctx.GetStub().GetState(modificationTrackingDisabledKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read modification tracking: %v", err)
+	}
+	if len(disabledBytes) > 0 {
+		return 0, nil
+	}
+
+	sequence, err := readIntOption(ctx, modificationSequenceKey)
+	if err != nil {
+		return 0, err
+	}
+
+	return sequence + 1, nil
+}
+
+// touchTokens assigns consecutive sequence numbers to the given tokens in one counter update
+// Every change reads and writes the shared counter, so token changes within a block are serialized by MVCC
+func touchTokens(ctx contractapi.TransactionContextInterface, tokenIDs []string) error {
+	disabledBytes, err := ctx.GetStub().GetState(modificationTrackingDisabledKey)
+	if err != nil {
+		return fmt.Errorf("failed to read modification tracking: %v", err)
+	}
+	if len(disabledBytes) > 0 {
+		return nil
+	}
+
+	sequence, err := readIntOption(ctx, modificationSequenceKey)
+	if err != nil {
+		return err
+	}
+
+	for _, tokenID := range tokenIDs {
+		sequence++
+
+		// Remove the previous index entry so that each token appears once, at its latest sequence
+		previous, err := readTokenSequence(ctx, tokenID)
+		if err != nil {
+			return err
+		}
+		if previous > 0 {
+			previousKey := modifiedIndexKey(previous, tokenID)
+			err = ctx.GetStub().DelState(previousKey)
+			if err != nil {
+				return fmt.Errorf("failed to delete modified index %s: %v", previousKey, err)
+			}
+		}
+
+		indexKey := modifiedIndexKey(sequence, tokenID)
+		err = ctx.GetStub().PutState(indexKey, []byte{0x00})
+		if err != nil {
+			return fmt.Errorf("failed to put modified index %s: %v", indexKey, err)
+		}
+
+		sequenceKey, err := ctx.GetStub().CreateCompositeKey(tokenSequencePrefix, []string{tokenID})
+		if err != nil {
+			return fmt.Errorf("failed to create the composite key for prefix %s: %v", tokenSequencePrefix, err)
+		}
+		err = ctx.GetStub().PutState(sequenceKey, []byte(strconv.Itoa(sequence)))
+		if err != nil {
+			return fmt.Errorf("failed to put sequence of token %s: %v", tokenID, err)
+		}
+	}
+
+	err = ctx.GetStub().PutState(modificationSequenceKey, []byte(strconv.Itoa(sequence)))
+	if err != nil {
+		return fmt.Errorf("failed to put modification sequence: %v", err)
+	}
+
+	return nil
+}
+
+func readTokenSequence(ctx contractapi.TransactionContextInterface, tokenID string) (int, error) {
+	sequenceKey, err := ctx.GetStub().CreateCompositeKey(tokenSequencePrefix, []string{tokenID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", tokenSequencePrefix, err)
+	}
+	sequenceBytes, err := ctx.GetStub().GetState(sequenceKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sequence of token %s: %v", tokenID, err)
+	}
+	if len(sequenceBytes) == 0 {
+		return 0, nil
+	}
+
+	sequence, _ := strconv.Atoi(string(sequenceBytes)) // Error handling not needed since Itoa() was used when setting the sequence, guaranteeing it was an integer.
+
+	return sequence, nil
+}
+
+// modifiedIndexKey zero-pads the sequence number so that index keys sort numerically
+func modifiedIndexKey(sequence int, tokenID string) string {
+	return fmt.Sprintf("%s%020d:%s", modifiedIndexPrefix, sequence, tokenID)
+}
+
+func splitModifiedIndexKey(indexKey string) (int, string) {
+	parts := strings.SplitN(strings.TrimPrefix(indexKey, modifiedIndexPrefix), ":", 2)
+	sequence, _ := strconv.Atoi(parts[0]) // Error handling not needed since modifiedIndexKey() was used when creating the key, guaranteeing it was an integer.
+
+	return sequence, parts[1]
+}
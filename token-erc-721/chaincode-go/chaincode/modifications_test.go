@@ -0,0 +1,105 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTokensModifiedSince(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	ctx := prepMocks(stub, org1MSP, minter)
+
+	sequence, err := tokenContract.GetTokenSequence(ctx, "101")
+	require.NoError(t, err)
+	require.Equal(t, 1, sequence)
+
+	page, err := tokenContract.GetTokensModifiedSince(ctx, 0, 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Records, 3)
+	require.Equal(t, "", page.Bookmark)
+
+	// A transfer bumps the token's sequence and moves it to the end of the since-query
+	_, err = tokenContract.TransferFrom(ctx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	sequence, err = tokenContract.GetTokenSequence(ctx, "101")
+	require.NoError(t, err)
+	require.Equal(t, 4, sequence)
+
+	page, err = tokenContract.GetTokensModifiedSince(ctx, 3, 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	require.Equal(t, 4, page.Records[0].Sequence)
+	require.Equal(t, "101", page.Records[0].TokenID)
+	require.Equal(t, recipient, page.Records[0].Token.Owner)
+
+	// Each token is listed once, at its latest sequence
+	page, err = tokenContract.GetTokensModifiedSince(ctx, 0, 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Records, 3)
+	require.Equal(t, []string{"102", "103", "101"}, []string{page.Records[0].TokenID, page.Records[1].TokenID, page.Records[2].TokenID})
+
+	// A burn is reported so that caches can evict the token
+	_, err = tokenContract.Burn(ctx, "102")
+	require.NoError(t, err)
+	page, err = tokenContract.GetTokensModifiedSince(ctx, 4, 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	require.Equal(t, chaincode.ModifiedToken{Sequence: 5, TokenID: "102", Burned: true}, page.Records[0])
+}
+
+func TestGetTokensModifiedSincePagination(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	ctx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.GetTokensModifiedSince(ctx, 0, 0, "")
+	require.EqualError(t, err, "page size must be a positive integer")
+
+	page, err := tokenContract.GetTokensModifiedSince(ctx, 0, 2, "")
+	require.NoError(t, err)
+	require.Len(t, page.Records, 2)
+	require.Equal(t, "2", page.Bookmark)
+
+	page, err = tokenContract.GetTokensModifiedSince(ctx, 0, 2, page.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	require.Equal(t, "103", page.Records[0].TokenID)
+	require.Equal(t, "", page.Bookmark)
+}
+
+func TestSetModificationTracking(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	ctx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetModificationTracking(prepMocks(stub, org2MSP, recipient), false)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	// Changes made while tracking is off are not indexed
+	err = tokenContract.SetModificationTracking(ctx, false)
+	require.NoError(t, err)
+	mintTokens(t, stub, "102")
+	_, err = tokenContract.TransferFrom(ctx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	page, err := tokenContract.GetTokensModifiedSince(ctx, 0, 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	require.Equal(t, 1, page.Records[0].Sequence)
+
+	// Once back on, numbering continues from the last tracked change
+	err = tokenContract.SetModificationTracking(ctx, true)
+	require.NoError(t, err)
+	mintTokens(t, stub, "103")
+
+	sequence, err := tokenContract.GetTokenSequence(ctx, "103")
+	require.NoError(t, err)
+	require.Equal(t, 2, sequence)
+}
@@ -0,0 +1,284 @@
+package chaincode_test
+
+import (
+	"sort"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+These tests model how Fabric validates transactions of the same block. Every transaction is simulated
+against the same committed state, recording its read set (keys and ranges it read) and write set.
+At commit, a transaction is invalidated with an MVCC_READ_CONFLICT if an earlier transaction of the
+block wrote a key it read, or a phantom read conflict if an earlier write falls in a range it read.
+
+Known conflicts, which these tests document:
+  - Any two changes of the same token conflict on its nft record, as they must.
+  - While modification tracking is on (the default), any two token changes conflict on the
+    contract-wide modificationSequence counter; SetModificationTracking(false) removes it.
+  - Any two mints conflict on the totalMinted counter, and any two burns on totalBurned.
+  - With the cap transfer rule enabled, transfers to the same recipient conflict on the range read of
+    the recipient's balance.
+*/
+
+// keyRange is a range read, from start inclusive to end exclusive
+type keyRange struct {
+	start string
+	end   string
+}
+
+// rwSet records the keys a simulated transaction read and wrote
+type rwSet struct {
+	reads  map[string]bool
+	ranges []keyRange
+	writes map[string]bool
+}
+
+// recordingStub is a MockStub that records the read/write set of the transaction it simulates
+type recordingStub struct {
+	*shimtest.MockStub
+	rw *rwSet
+}
+
+func (stub *recordingStub) GetState(key string) ([]byte, error) {
+	stub.rw.reads[key] = true
+	return stub.MockStub.GetState(key)
+}
+
+func (stub *recordingStub) PutState(key string, value []byte) error {
+	stub.rw.writes[key] = true
+	return stub.MockStub.PutState(key, value)
+}
+
+func (stub *recordingStub) DelState(key string) error {
+	stub.rw.writes[key] = true
+	return stub.MockStub.DelState(key)
+}
+
+func (stub *recordingStub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	stub.rw.ranges = append(stub.rw.ranges, keyRange{startKey, endKey})
+	return stub.MockStub.GetStateByRange(startKey, endKey)
+}
+
+func (stub *recordingStub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	startKey, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	stub.rw.ranges = append(stub.rw.ranges, keyRange{startKey, startKey + string(utf8.MaxRune)})
+	return stub.MockStub.GetStateByPartialCompositeKey(objectType, attributes)
+}
+
+// simulate runs setup to build the committed state on a fresh stub, then records the read/write set of tx
+func simulate(t *testing.T, setup func(*shimtest.MockStub), tx func(shim.ChaincodeStubInterface) error) *rwSet {
+	stub := newMockStub()
+	setTxTime(stub, 1000)
+	setup(stub)
+
+	recording := &recordingStub{stub, &rwSet{reads: map[string]bool{}, writes: map[string]bool{}}}
+	require.NoError(t, tx(recording))
+
+	return recording.rw
+}
+
+// conflicts returns the keys written by an earlier transaction that invalidate a later one
+func conflicts(earlier, later *rwSet) []string {
+	keys := []string{}
+	for key := range earlier.writes {
+		conflict := later.reads[key]
+		for _, r := range later.ranges {
+			if key >= r.start && key < r.end {
+				conflict = true
+			}
+		}
+		if conflict {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// sharedWrites returns the keys written by both transactions
+func sharedWrites(a, b *rwSet) []string {
+	keys := []string{}
+	for key := range a.writes {
+		if b.writes[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// commitBlock applies the simulated transactions in order, skipping those that conflict with an earlier
+// valid transaction, and returns the committed state
+func commitBlock(t *testing.T, setup func(*shimtest.MockStub), txs ...func(shim.ChaincodeStubInterface) error) *shimtest.MockStub {
+	rwSets := make([]*rwSet, len(txs))
+	for i, tx := range txs {
+		rwSets[i] = simulate(t, setup, tx)
+	}
+
+	stub := newMockStub()
+	setTxTime(stub, 1000)
+	setup(stub)
+
+	valid := []*rwSet{}
+	for i, tx := range txs {
+		invalid := false
+		for _, earlier := range valid {
+			if len(conflicts(earlier, rwSets[i])) > 0 {
+				invalid = true
+			}
+		}
+		if invalid {
+			continue
+		}
+		require.NoError(t, tx(stub))
+		valid = append(valid, rwSets[i])
+	}
+
+	return stub
+}
+
+func transfer(from, to, tokenID string) func(shim.ChaincodeStubInterface) error {
+	return func(stub shim.ChaincodeStubInterface) error {
+		_, err := new(chaincode.SmartContract).TransferFrom(prepMocks(stub, org1MSP, from), from, to, tokenID)
+		return err
+	}
+}
+
+func mintTwo(stub *shimtest.MockStub) {
+	tokenContract := chaincode.SmartContract{}
+	ctx := prepMocks(stub, org1MSP, minter)
+	tokenContract.MintWithTokenURI(ctx, "101", "")
+	tokenContract.MintWithTokenURI(ctx, "102", "")
+}
+
+func requireBalance(t *testing.T, stub shim.ChaincodeStubInterface, owner string, expected int) {
+	balance, err := new(chaincode.SmartContract).BalanceOf(prepMocks(stub, org1MSP, owner), owner)
+	require.NoError(t, err)
+	require.Equal(t, expected, balance, "balance of %s", owner)
+}
+
+func TestMVCCTransfersOfSameTokenConflict(t *testing.T) {
+	tx1 := transfer(minter, recipient, "101")
+	tx2 := transfer(minter, operator, "101")
+
+	rw1 := simulate(t, mintTwo, tx1)
+	rw2 := simulate(t, mintTwo, tx2)
+
+	nftKey, err := newMockStub().CreateCompositeKey("nft", []string{"101"})
+	require.NoError(t, err)
+	require.Contains(t, conflicts(rw1, rw2), nftKey)
+
+	// Only the first transfer commits, so the token is not double-spent and balances stay consistent
+	stub := commitBlock(t, mintTwo, tx1, tx2)
+	owner, err := new(chaincode.SmartContract).OwnerOf(prepMocks(stub, org1MSP, minter), "101")
+	require.NoError(t, err)
+	require.Equal(t, recipient, owner)
+	requireBalance(t, stub, minter, 1)
+	requireBalance(t, stub, recipient, 1)
+	requireBalance(t, stub, operator, 0)
+}
+
+func TestMVCCTransfersOfDistinctTokensWithTracking(t *testing.T) {
+	tx1 := transfer(minter, recipient, "101")
+	tx2 := transfer(minter, operator, "102")
+
+	rw1 := simulate(t, mintTwo, tx1)
+	rw2 := simulate(t, mintTwo, tx2)
+
+	// The modification counter is the only key both transfers touch
+	require.Equal(t, []string{"modificationSequence"}, conflicts(rw1, rw2))
+	require.Equal(t, []string{"modificationSequence"}, sharedWrites(rw1, rw2))
+}
+
+func TestMVCCTransfersOfDistinctTokensDoNotShareKeys(t *testing.T) {
+	setup := func(stub *shimtest.MockStub) {
+		mintTwo(stub)
+		new(chaincode.SmartContract).SetModificationTracking(prepMocks(stub, org1MSP, minter), false)
+	}
+	tx1 := transfer(minter, recipient, "101")
+	tx2 := transfer(minter, operator, "102")
+
+	rw1 := simulate(t, setup, tx1)
+	rw2 := simulate(t, setup, tx2)
+
+	require.Empty(t, conflicts(rw1, rw2))
+	require.Empty(t, conflicts(rw2, rw1))
+	require.Empty(t, sharedWrites(rw1, rw2))
+
+	// Both transfers commit in the same block
+	stub := commitBlock(t, setup, tx1, tx2)
+	requireBalance(t, stub, minter, 0)
+	requireBalance(t, stub, recipient, 1)
+	requireBalance(t, stub, operator, 1)
+}
+
+func TestMVCCApproveAndTransferOfSameTokenConflict(t *testing.T) {
+	approve := func(stub shim.ChaincodeStubInterface) error {
+		_, err := new(chaincode.SmartContract).Approve(prepMocks(stub, org1MSP, minter), operator, "101")
+		return err
+	}
+	tx := transfer(minter, recipient, "101")
+
+	nftKey, err := newMockStub().CreateCompositeKey("nft", []string{"101"})
+	require.NoError(t, err)
+	require.Contains(t, conflicts(simulate(t, mintTwo, approve), simulate(t, mintTwo, tx)), nftKey)
+}
+
+func TestMVCCMintsConflictOnCounters(t *testing.T) {
+	mint := func(tokenID string) func(shim.ChaincodeStubInterface) error {
+		return func(stub shim.ChaincodeStubInterface) error {
+			_, err := new(chaincode.SmartContract).MintWithTokenURI(prepMocks(stub, org1MSP, minter), tokenID, "")
+			return err
+		}
+	}
+	setup := func(stub *shimtest.MockStub) {
+		new(chaincode.SmartContract).SetModificationTracking(prepMocks(stub, org1MSP, minter), false)
+	}
+
+	// Only the cumulative minted counter is shared once modification tracking is off
+	rw1 := simulate(t, setup, mint("101"))
+	rw2 := simulate(t, setup, mint("102"))
+	require.Equal(t, []string{"totalMinted"}, conflicts(rw1, rw2))
+
+	// The second mint is invalidated rather than under-counting the minted total
+	stub := commitBlock(t, setup, mint("101"), mint("102"))
+	minted, err := new(chaincode.SmartContract).GetTotalMinted(prepMocks(stub, org1MSP, minter))
+	require.NoError(t, err)
+	require.Equal(t, 1, minted)
+	requireBalance(t, stub, minter, 1)
+}
+
+func TestMVCCCapRuleConflictsOnRecipientBalance(t *testing.T) {
+	setup := func(stub *shimtest.MockStub) {
+		mintTwo(stub)
+		ctx := prepMocks(stub, org1MSP, minter)
+		tokenContract := chaincode.SmartContract{}
+		tokenContract.SetModificationTracking(ctx, false)
+		tokenContract.SetBalanceCap(ctx, 1)
+		tokenContract.EnableTransferRule(ctx, "cap", true)
+	}
+	tx1 := transfer(minter, recipient, "101")
+	tx2 := transfer(minter, recipient, "102")
+
+	rw1 := simulate(t, setup, tx1)
+	rw2 := simulate(t, setup, tx2)
+
+	balanceKey, err := newMockStub().CreateCompositeKey("balance", []string{recipient, "101"})
+	require.NoError(t, err)
+	require.Equal(t, []string{balanceKey}, conflicts(rw1, rw2))
+
+	// The phantom read keeps the recipient within the cap
+	stub := commitBlock(t, setup, tx1, tx2)
+	requireBalance(t, stub, recipient, 1)
+}
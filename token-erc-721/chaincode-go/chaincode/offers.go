@@ -0,0 +1,255 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+)
+
+// Define objectType names for prefix
+const offerPrefix = "offer"
+
+// TransferOffer is a transfer the owner of a token started, waiting for the recipient to accept it
+type TransferOffer struct {
+	TokenID   string `json:"tokenId"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// OfferTransfer offers a non-fungible token owned by the caller to a recipient, who completes the transfer with AcceptTransfer
+// The token stays with the owner until then, and cannot be transferred to anyone else while the offer is pending.
+// Editions have no single owner, so their copies cannot be offered.
+func (s *SmartContract) OfferTransfer(ctx contractapi.TransactionContextInterface, to string, tokenID string) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if nft.isEdition() {
+		return fmt.Errorf("token %s is an edition, transfer its copies with TransferFrom instead", tokenID)
+	}
+	if nft.Owner != sender {
+		return fmt.Errorf("non-fungible token %s is not owned by %s", tokenID, sender)
+	}
+	if to == zeroAddress {
+		return fmt.Errorf("invalid recipient %s", to)
+	}
+	if to == "" || to == sender {
+		return fmt.Errorf("a transfer offer needs a recipient other than the owner")
+	}
+
+	offer, err := readOffer(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if offer != nil {
+		return fmt.Errorf("token %s has a pending transfer offer to %s", tokenID, offer.To)
+	}
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	return putOffer(ctx, &TransferOffer{TokenID: tokenID, From: sender, To: to, Timestamp: now})
+}
+
+// AcceptTransfer completes the transfer of a token offered to the caller
+// The transfer rules are checked now, not when the offer was made.
+// This function triggers a Transfer event
+func (s *SmartContract) AcceptTransfer(ctx contractapi.TransactionContextInterface, tokenID string) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	offer, err := readOffer(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if offer == nil || offer.To != sender {
+		return fmt.Errorf("token %s has no pending transfer offer to %s", tokenID, sender)
+	}
+
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	// The offer is void if the token left its owner another way
+	if nft.Owner != offer.From {
+		return fmt.Errorf("the offer of token %s is no longer valid, %s does not own it", tokenID, offer.From)
+	}
+
+	restrictedTo, err := readRestriction(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if restrictedTo != "" {
+		clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+		if err != nil {
+			return fmt.Errorf("failed to get MSPID: %v", err)
+		}
+		if clientMSPID != restrictedTo {
+			return fmt.Errorf("token %s is restricted to %s and cannot be accepted by a client of %s", tokenID, restrictedTo, clientMSPID)
+		}
+	}
+
+	err = deleteOffer(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	err = moveToken(ctx, nft, offer.From, sender)
+	if err != nil {
+		return err
+	}
+
+	err = appendOwnerChange(ctx, tokenID, OwnerChange{From: offer.From, To: sender})
+	if err != nil {
+		return err
+	}
+	err = recordActivity(ctx, tokenID, offer.From, sender, 1)
+	if err != nil {
+		return err
+	}
+
+	// Emit the Transfer event
+	err = emitEvent(ctx, "Transfer", events.TransferEvent{From: offer.From, To: sender, TokenID: tokenID})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("token %s transferred from %s to %s", tokenID, offer.From, sender)
+
+	return nil
+}
+
+// CancelOffer withdraws the pending transfer offer of a token, which only the owner who made it can do
+func (s *SmartContract) CancelOffer(ctx contractapi.TransactionContextInterface, tokenID string) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	offer, err := readOffer(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if offer == nil {
+		return fmt.Errorf("token %s has no pending transfer offer", tokenID)
+	}
+	if offer.From != sender {
+		return fmt.Errorf("the transfer offer of token %s was not made by %s", tokenID, sender)
+	}
+
+	return deleteOffer(ctx, tokenID)
+}
+
+// GetPendingOffersTo returns the pending transfer offers an account can accept
+func (s *SmartContract) GetPendingOffersTo(ctx contractapi.TransactionContextInterface, account string) ([]*TransferOffer, error) {
+	return findOffers(ctx, func(offer *TransferOffer) bool { return offer.To == account })
+}
+
+// GetPendingOffersFrom returns the pending transfer offers an account made and can cancel
+func (s *SmartContract) GetPendingOffersFrom(ctx contractapi.TransactionContextInterface, account string) ([]*TransferOffer, error) {
+	return findOffers(ctx, func(offer *TransferOffer) bool { return offer.From == account })
+}
+
+// Helper Functions
+
+// readOffer returns the pending transfer offer of a token, or nil if there is none
+func readOffer(ctx contractapi.TransactionContextInterface, tokenID string) (*TransferOffer, error) {
+	offerKey, err := ctx.GetStub().CreateCompositeKey(offerPrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", offerPrefix, err)
+	}
+	offerBytes, err := ctx.GetStub().GetState(offerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transfer offer of token %s: %v", tokenID, err)
+	}
+	if len(offerBytes) == 0 {
+		return nil, nil
+	}
+
+	var offer TransferOffer
+	err = json.Unmarshal(offerBytes, &offer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transfer offer of token %s: %v", tokenID, err)
+	}
+
+	return &offer, nil
+}
+
+func putOffer(ctx contractapi.TransactionContextInterface, offer *TransferOffer) error {
+	offerKey, err := ctx.GetStub().CreateCompositeKey(offerPrefix, []string{offer.TokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", offerPrefix, err)
+	}
+	offerJSON, err := json.Marshal(offer)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(offerKey, offerJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put transfer offer of token %s: %v", offer.TokenID, err)
+	}
+
+	return nil
+}
+
+func deleteOffer(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	offerKey, err := ctx.GetStub().CreateCompositeKey(offerPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", offerPrefix, err)
+	}
+	err = ctx.GetStub().DelState(offerKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete transfer offer of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
+
+// findOffers returns the pending transfer offers that match
+// Offers are keyed by token, so every offer is visited
+func findOffers(ctx contractapi.TransactionContextInterface, match func(*TransferOffer) bool) ([]*TransferOffer, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(offerPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer offer keys: %v", err)
+	}
+	defer iterator.Close()
+
+	offers := []*TransferOffer{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transfer offer key: %v", err)
+		}
+
+		var offer TransferOffer
+		err = json.Unmarshal(queryResponse.Value, &offer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transfer offer %s: %v", queryResponse.Key, err)
+		}
+		if match(&offer) {
+			offers = append(offers, &offer)
+		}
+	}
+
+	return offers, nil
+}
@@ -0,0 +1,132 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptTransfer(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	err := tokenContract.OfferTransfer(recipientCtx, operator, "101")
+	require.EqualError(t, err, "non-fungible token 101 is not owned by recipient")
+
+	err = tokenContract.OfferTransfer(minterCtx, recipient, "101")
+	require.NoError(t, err)
+
+	// The token cannot be offered twice or moved elsewhere while the offer is pending
+	err = tokenContract.OfferTransfer(minterCtx, operator, "101")
+	require.EqualError(t, err, "token 101 has a pending transfer offer to recipient")
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "101")
+	require.EqualError(t, err, "token 101 has a pending transfer offer to recipient")
+
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, minter, owner)
+
+	err = tokenContract.AcceptTransfer(prepMocks(stub, org2MSP, operator), "101")
+	require.EqualError(t, err, "token 101 has no pending transfer offer to operator")
+
+	drainEvents(stub)
+	err = tokenContract.AcceptTransfer(recipientCtx, "101")
+	require.NoError(t, err)
+
+	owner, err = tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, recipient, owner)
+	emitted := drainEvents(stub)
+	require.Len(t, emitted, 1)
+	require.Equal(t, "Transfer", emitted[0].EventName)
+
+	// The offer is used up
+	err = tokenContract.AcceptTransfer(recipientCtx, "101")
+	require.EqualError(t, err, "token 101 has no pending transfer offer to recipient")
+	_, err = tokenContract.TransferFrom(recipientCtx, recipient, operator, "101")
+	require.NoError(t, err)
+}
+
+func TestCancelOffer(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	err := tokenContract.CancelOffer(minterCtx, "101")
+	require.EqualError(t, err, "token 101 has no pending transfer offer")
+
+	err = tokenContract.OfferTransfer(minterCtx, recipient, "101")
+	require.NoError(t, err)
+
+	err = tokenContract.CancelOffer(recipientCtx, "101")
+	require.EqualError(t, err, "the transfer offer of token 101 was not made by recipient")
+
+	err = tokenContract.CancelOffer(minterCtx, "101")
+	require.NoError(t, err)
+
+	err = tokenContract.AcceptTransfer(recipientCtx, "101")
+	require.EqualError(t, err, "token 101 has no pending transfer offer to recipient")
+
+	// The token can be offered again
+	err = tokenContract.OfferTransfer(minterCtx, operator, "101")
+	require.NoError(t, err)
+}
+
+func TestBurnClearsOffer(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.OfferTransfer(minterCtx, recipient, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(minterCtx, "101")
+	require.NoError(t, err)
+
+	// A token minted again with the same ID is not bound by the old offer
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "101", "https://example.com/nft/101")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "101")
+	require.NoError(t, err)
+}
+
+func TestGetPendingOffers(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	setTxTime(stub, 1000)
+	err := tokenContract.OfferTransfer(minterCtx, recipient, "101")
+	require.NoError(t, err)
+	err = tokenContract.OfferTransfer(minterCtx, operator, "102")
+	require.NoError(t, err)
+
+	offers, err := tokenContract.GetPendingOffersFrom(minterCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.TransferOffer{
+		{TokenID: "101", From: minter, To: recipient, Timestamp: 1000},
+		{TokenID: "102", From: minter, To: operator, Timestamp: 1000},
+	}, offers)
+
+	offers, err = tokenContract.GetPendingOffersTo(minterCtx, recipient)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.TransferOffer{{TokenID: "101", From: minter, To: recipient, Timestamp: 1000}}, offers)
+
+	offers, err = tokenContract.GetPendingOffersFrom(minterCtx, recipient)
+	require.NoError(t, err)
+	require.Empty(t, offers)
+
+	// Accepted offers are no longer pending
+	err = tokenContract.AcceptTransfer(prepMocks(stub, org2MSP, recipient), "101")
+	require.NoError(t, err)
+	offers, err = tokenContract.GetPendingOffersTo(minterCtx, recipient)
+	require.NoError(t, err)
+	require.Empty(t, offers)
+}
@@ -0,0 +1,178 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const ownerChangesPrefix = "ownerChanges"
+
+// OwnerChange is one entry in the ownership log of a token
+// From is the zero address "0x0" for a mint and To is the zero address for a burn. SalePrice is only set when Sold is true.
+// A reissue is noted with From and To both the owner, and PreviousURI the token URI it replaced.
+type OwnerChange struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	TxID        string `json:"txId"`
+	Timestamp   int64  `json:"timestamp"`
+	Sold        bool   `json:"sold,omitempty" metadata:",optional"`
+	SalePrice   int    `json:"salePrice,omitempty" metadata:",optional"`
+	Reissued    bool   `json:"reissued,omitempty" metadata:",optional"`
+	PreviousURI string `json:"previousURI,omitempty" metadata:",optional"`
+}
+
+// GetOwnerChangeEvents returns the ownership log of a token, oldest change first
+// The log is kept after a burn, so the provenance of a burned token can still be read.
+// It is cheaper to read than the key history, which holds every update of the token record.
+func (s *SmartContract) GetOwnerChangeEvents(ctx contractapi.TransactionContextInterface, tokenID string) ([]OwnerChange, error) {
+	changes, err := readOwnerChanges(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		// Tokens minted before the log was kept have no entries, but unknown tokens are an error
+		_, err = readNFT(ctx, tokenID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+// TransferEdge is a directed edge of the transfer graph, one change of owner of a token
+type TransferEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	TokenID   string `json:"tokenId"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// TransferEdgesPage is one page of ExportTransferEdges, with the bookmark to pass for the next page
+// The bookmark is empty on the last page
+type TransferEdgesPage struct {
+	Edges    []TransferEdge `json:"edges"`
+	Bookmark string         `json:"bookmark"`
+}
+
+// ExportTransferEdges returns the ownership logs of all tokens as the edges of a transfer graph
+// A page holds the edges of up to pageSize tokens, so the history of a token is never split across pages.
+// Mints start and burns end at the zero address "0x0", and the logs of burned tokens are included.
+// Reissues do not move a token and are left out.
+func (s *SmartContract) ExportTransferEdges(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*TransferEdgesPage, error) {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(ownerChangesPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner changes keys: %v", err)
+	}
+	defer iterator.Close()
+
+	page := &TransferEdgesPage{Edges: []TransferEdge{}}
+	tokens := 0
+	lastKey := ""
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read owner changes key: %v", err)
+		}
+
+		// The bookmark is the key of the last ownership log of the previous page
+		if bookmark != "" && queryResponse.Key <= bookmark {
+			continue
+		}
+
+		// Stop once the page is full; the remaining logs belong to the next page
+		if tokens == pageSize {
+			page.Bookmark = lastKey
+			break
+		}
+
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split the composite key %s: %v", queryResponse.Key, err)
+		}
+		tokenID := compositeKeyParts[0]
+
+		var changes []OwnerChange
+		err = json.Unmarshal(queryResponse.Value, &changes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal owner changes of token %s: %v", tokenID, err)
+		}
+		for _, change := range changes {
+			if change.Reissued {
+				continue
+			}
+			page.Edges = append(page.Edges, TransferEdge{From: change.From, To: change.To, TokenID: tokenID, Timestamp: change.Timestamp})
+		}
+		tokens++
+		lastKey = queryResponse.Key
+	}
+
+	return page, nil
+}
+
+// Helper Functions
+
+// appendOwnerChange adds a change to the ownership log of a token, stamped with the current transaction
+// A token changes owner or is reissued at most once per transaction, so the log written here is never overwritten in the same transaction
+func appendOwnerChange(ctx contractapi.TransactionContextInterface, tokenID string, change OwnerChange) error {
+	changes, err := readOwnerChanges(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	change.TxID = ctx.GetStub().GetTxID()
+	change.Timestamp = now
+	changes = append(changes, change)
+
+	changesKey, err := ctx.GetStub().CreateCompositeKey(ownerChangesPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", ownerChangesPrefix, err)
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(changesKey, changesJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put owner changes of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
+
+func readOwnerChanges(ctx contractapi.TransactionContextInterface, tokenID string) ([]OwnerChange, error) {
+	changesKey, err := ctx.GetStub().CreateCompositeKey(ownerChangesPrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", ownerChangesPrefix, err)
+	}
+	changesBytes, err := ctx.GetStub().GetState(changesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read owner changes of token %s: %v", tokenID, err)
+	}
+
+	changes := []OwnerChange{}
+	if len(changesBytes) > 0 {
+		err = json.Unmarshal(changesBytes, &changes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal owner changes of token %s: %v", tokenID, err)
+		}
+	}
+
+	return changes, nil
+}
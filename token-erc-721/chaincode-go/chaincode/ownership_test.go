@@ -0,0 +1,99 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOwnerChangeEvents(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	stub.MockTransactionStart("mintTx")
+	setTxTime(stub, 1000)
+	_, err := tokenContract.MintWithTokenURI(minterCtx, "101", "https://example.com/nft/101")
+	require.NoError(t, err)
+	stub.MockTransactionEnd("mintTx")
+
+	stub.MockTransactionStart("giftTx")
+	setTxTime(stub, 2000)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	stub.MockTransactionEnd("giftTx")
+
+	stub.MockTransactionStart("saleTx")
+	setTxTime(stub, 3000)
+	_, err = tokenContract.TransferSale(prepMocks(stub, org2MSP, recipient), recipient, operator, "101", 250)
+	require.NoError(t, err)
+	stub.MockTransactionEnd("saleTx")
+
+	changes, err := tokenContract.GetOwnerChangeEvents(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.OwnerChange{
+		{From: "0x0", To: minter, TxID: "mintTx", Timestamp: 1000},
+		{From: minter, To: recipient, TxID: "giftTx", Timestamp: 2000},
+		{From: recipient, To: operator, TxID: "saleTx", Timestamp: 3000, Sold: true, SalePrice: 250},
+	}, changes)
+
+	// The log outlives the token
+	stub.MockTransactionStart("burnTx")
+	setTxTime(stub, 4000)
+	_, err = tokenContract.Burn(prepMocks(stub, org2MSP, operator), "101")
+	require.NoError(t, err)
+	stub.MockTransactionEnd("burnTx")
+
+	changes, err = tokenContract.GetOwnerChangeEvents(minterCtx, "101")
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+	require.Equal(t, chaincode.OwnerChange{From: operator, To: "0x0", TxID: "burnTx", Timestamp: 4000}, changes[3])
+
+	_, err = tokenContract.GetOwnerChangeEvents(minterCtx, "999")
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
+
+func TestExportTransferEdges(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	setTxTime(stub, 1000)
+	mintTokens(t, stub, "101", "102")
+	setTxTime(stub, 2000)
+	_, err := tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	setTxTime(stub, 3000)
+	_, err = tokenContract.TransferFrom(prepMocks(stub, org2MSP, recipient), recipient, operator, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(minterCtx, "102")
+	require.NoError(t, err)
+
+	_, err = tokenContract.ExportTransferEdges(prepMocks(stub, org2MSP, recipient), 1, "")
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+	_, err = tokenContract.ExportTransferEdges(minterCtx, 0, "")
+	require.EqualError(t, err, "page size must be a positive integer")
+
+	// Each page holds the whole history of one token
+	page, err := tokenContract.ExportTransferEdges(minterCtx, 1, "")
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.TransferEdge{
+		{From: "0x0", To: minter, TokenID: "101", Timestamp: 1000},
+		{From: minter, To: recipient, TokenID: "101", Timestamp: 2000},
+		{From: recipient, To: operator, TokenID: "101", Timestamp: 3000},
+	}, page.Edges)
+	require.NotEmpty(t, page.Bookmark)
+
+	page, err = tokenContract.ExportTransferEdges(minterCtx, 1, page.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.TransferEdge{
+		{From: "0x0", To: minter, TokenID: "102", Timestamp: 1000},
+		{From: minter, To: "0x0", TokenID: "102", Timestamp: 3000},
+	}, page.Edges)
+	require.Empty(t, page.Bookmark)
+
+	page, err = tokenContract.ExportTransferEdges(minterCtx, 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Edges, 5)
+}
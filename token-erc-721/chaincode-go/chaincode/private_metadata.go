@@ -0,0 +1,164 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const privateMetadataPrefix = "privateMetadata"
+const privateCollectionPrefix = "privateCollection"
+
+// PrivateMetadata holds the fields of a token that are kept out of the public world state
+type PrivateMetadata struct {
+	TokenID string            `json:"tokenId"`
+	Fields  map[string]string `json:"fields"`
+}
+
+// MintWithPrivateMetadata mints a token whose public data is stored in the world state as usual, while
+// its sensitive fields are written to a private data collection.
+// The fields are passed in the transient map under "private_metadata" as {"collection": "...", "fields": {...}},
+// so that they do not appear in the transaction proposal recorded on the ledger.
+// This function triggers a Transfer event
+func (s *SmartContract) MintWithPrivateMetadata(ctx contractapi.TransactionContextInterface, tokenID string, publicURI string) (*Nft, error) {
+
+	// Get the private fields from the transient map
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("error getting transient: %v", err)
+	}
+
+	// Private fields are passed in the transient field, instead of func args
+	transientMetadataJSON, ok := transientMap["private_metadata"]
+	if !ok {
+		return nil, fmt.Errorf("private metadata not found in the transient map input")
+	}
+
+	type metadataTransientInput struct {
+		Collection string            `json:"collection"`
+		Fields     map[string]string `json:"fields"`
+	}
+
+	var metadataInput metadataTransientInput
+	err = json.Unmarshal(transientMetadataJSON, &metadataInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %v", err)
+	}
+	if metadataInput.Collection == "" {
+		return nil, fmt.Errorf("collection field must be a non-empty string")
+	}
+
+	nft, err := mintHelper(ctx, tokenID, publicURI, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataKey, err := ctx.GetStub().CreateCompositeKey(privateMetadataPrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", privateMetadataPrefix, err)
+	}
+	metadataJSON, err := json.Marshal(PrivateMetadata{TokenID: tokenID, Fields: metadataInput.Fields})
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutPrivateData(metadataInput.Collection, metadataKey, metadataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put private metadata of token %s into collection %s: %v", tokenID, metadataInput.Collection, err)
+	}
+
+	// Record the collection in the world state, so that burning the token can find and delete the private fields
+	collectionKey, err := ctx.GetStub().CreateCompositeKey(privateCollectionPrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", privateCollectionPrefix, err)
+	}
+	err = ctx.GetStub().PutState(collectionKey, []byte(metadataInput.Collection))
+	if err != nil {
+		return nil, fmt.Errorf("failed to put private collection of token %s: %v", tokenID, err)
+	}
+
+	return nft, nil
+}
+
+// GetPrivateMetadata reads the private fields of a token from a private data collection
+// Only peers of collection member orgs hold the data. The client must also be of the org of the peer it queries,
+// so that one org's clients cannot read another org's collection through that org's peer.
+func (s *SmartContract) GetPrivateMetadata(ctx contractapi.TransactionContextInterface, collection string, tokenID string) (*PrivateMetadata, error) {
+	err := verifyClientOrgMatchesPeerOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataKey, err := ctx.GetStub().CreateCompositeKey(privateMetadataPrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", privateMetadataPrefix, err)
+	}
+	metadataBytes, err := ctx.GetStub().GetPrivateData(collection, metadataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private metadata of token %s from collection %s: %v", tokenID, collection, err)
+	}
+	if len(metadataBytes) == 0 {
+		return nil, fmt.Errorf("no private metadata for token %s in collection %s", tokenID, collection)
+	}
+
+	var metadata PrivateMetadata
+	err = json.Unmarshal(metadataBytes, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private metadata of token %s: %v", tokenID, err)
+	}
+
+	return &metadata, nil
+}
+
+// Helper Functions
+
+// verifyClientOrgMatchesPeerOrg is an internal function used to verify the client org id matches the peer org id
+func verifyClientOrgMatchesPeerOrg(ctx contractapi.TransactionContextInterface) error {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed getting the client's MSPID: %v", err)
+	}
+	peerMSPID, err := shim.GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed getting the peer's MSPID: %v", err)
+	}
+
+	if clientMSPID != peerMSPID {
+		return fmt.Errorf("client from org %v is not authorized to read or write private data from an org %v peer", clientMSPID, peerMSPID)
+	}
+
+	return nil
+}
+
+// deletePrivateMetadata removes the private fields of a token, and with them their hash on the ledger
+func deletePrivateMetadata(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	collectionKey, err := ctx.GetStub().CreateCompositeKey(privateCollectionPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", privateCollectionPrefix, err)
+	}
+	collectionBytes, err := ctx.GetStub().GetState(collectionKey)
+	if err != nil {
+		return fmt.Errorf("failed to read private collection of token %s: %v", tokenID, err)
+	}
+	if len(collectionBytes) == 0 {
+		return nil
+	}
+	collection := string(collectionBytes)
+
+	metadataKey, err := ctx.GetStub().CreateCompositeKey(privateMetadataPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", privateMetadataPrefix, err)
+	}
+	err = ctx.GetStub().DelPrivateData(collection, metadataKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete private metadata of token %s from collection %s: %v", tokenID, collection, err)
+	}
+	err = ctx.GetStub().DelState(collectionKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete private collection of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package chaincode_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateMetadata(t *testing.T) {
+	os.Setenv("CORE_PEER_LOCALMSPID", org1MSP)
+	defer os.Unsetenv("CORE_PEER_LOCALMSPID")
+
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.MintWithPrivateMetadata(minterCtx, "101", "https://example.com/nft/101")
+	require.EqualError(t, err, "private metadata not found in the transient map input")
+
+	stub.TransientMap = map[string][]byte{
+		"private_metadata": []byte(`{"collection":"Org1MSPPrivateCollection","fields":{"holderName":"Alice"}}`),
+	}
+	nft, err := tokenContract.MintWithPrivateMetadata(minterCtx, "101", "https://example.com/nft/101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Nft{TokenID: "101", Owner: minter, Creator: minter, MintTxID: "tx1", TokenURI: "https://example.com/nft/101"}, nft)
+
+	// The private fields are kept out of the public token data
+	tokenURI, err := tokenContract.TokenURI(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/nft/101", tokenURI)
+	nftKey, err := stub.CreateCompositeKey("nft", []string{"101"})
+	require.NoError(t, err)
+	require.NotContains(t, string(stub.State[nftKey]), "Alice")
+
+	metadata, err := tokenContract.GetPrivateMetadata(minterCtx, "Org1MSPPrivateCollection", "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.PrivateMetadata{TokenID: "101", Fields: map[string]string{"holderName": "Alice"}}, metadata)
+
+	_, err = tokenContract.GetPrivateMetadata(minterCtx, "Org2MSPPrivateCollection", "101")
+	require.EqualError(t, err, "no private metadata for token 101 in collection Org2MSPPrivateCollection")
+
+	_, err = tokenContract.GetPrivateMetadata(prepMocks(stub, org2MSP, recipient), "Org1MSPPrivateCollection", "101")
+	require.EqualError(t, err, "client from org Org2MSP is not authorized to read or write private data from an org Org1MSP peer")
+}
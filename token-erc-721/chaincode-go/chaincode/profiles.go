@@ -0,0 +1,115 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const profilePrefix = "profile"
+
+// AccountProfile is the optional display information an account attaches to itself
+type AccountProfile struct {
+	Account   string `json:"account"`
+	Name      string `json:"name"`
+	AvatarURI string `json:"avatarURI"`
+}
+
+// AccountOverview combines what an owner checks before approving an account
+// Registered is true once the account has set a profile, which is then included.
+type AccountOverview struct {
+	Account    string          `json:"account"`
+	Registered bool            `json:"registered"`
+	Balance    int             `json:"balance"`
+	Profile    *AccountProfile `json:"profile,omitempty" metadata:",optional"`
+	Handle     string          `json:"handle,omitempty" metadata:",optional"`
+}
+
+// SetAccountProfile sets the display name and avatar URI of the calling account
+func (s *SmartContract) SetAccountProfile(ctx contractapi.TransactionContextInterface, name string, avatarURI string) error {
+
+	// Get ID of submitting client identity
+	account, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	profileKey, err := ctx.GetStub().CreateCompositeKey(profilePrefix, []string{account})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", profilePrefix, err)
+	}
+	profileJSON, err := json.Marshal(AccountProfile{Account: account, Name: name, AvatarURI: avatarURI})
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(profileKey, profileJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put profile of %s: %v", account, err)
+	}
+
+	return nil
+}
+
+// GetAccountProfile returns the profile of an account, with an empty name and avatar URI if none was set
+func (s *SmartContract) GetAccountProfile(ctx contractapi.TransactionContextInterface, account string) (*AccountProfile, error) {
+	profile, err := readProfile(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return &AccountProfile{Account: account}, nil
+	}
+
+	return profile, nil
+}
+
+// GetAccountOverview returns whether an account is registered, its balance, its profile and its handle in one call
+func (s *SmartContract) GetAccountOverview(ctx contractapi.TransactionContextInterface, account string) (*AccountOverview, error) {
+	balance, err := balanceOf(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := readProfile(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := readHandleEntry(ctx, accountHandlePrefix, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountOverview{Account: account, Registered: profile != nil, Balance: balance, Profile: profile, Handle: handle}, nil
+}
+
+// SetRequireRecipientProfile sets whether transfers are rejected when the recipient has not set an account profile
+// It is a shorthand for enabling the profile transfer rule
+func (s *SmartContract) SetRequireRecipientProfile(ctx contractapi.TransactionContextInterface, required bool) error {
+	return s.EnableTransferRule(ctx, "profile", required)
+}
+
+// Helper Functions
+
+// readProfile returns the profile of an account, or nil if none was set
+func readProfile(ctx contractapi.TransactionContextInterface, account string) (*AccountProfile, error) {
+	profileKey, err := ctx.GetStub().CreateCompositeKey(profilePrefix, []string{account})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", profilePrefix, err)
+	}
+	profileBytes, err := ctx.GetStub().GetState(profileKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile of %s: %v", account, err)
+	}
+	if len(profileBytes) == 0 {
+		return nil, nil
+	}
+
+	var profile AccountProfile
+	err = json.Unmarshal(profileBytes, &profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal profile of %s: %v", account, err)
+	}
+
+	return &profile, nil
+}
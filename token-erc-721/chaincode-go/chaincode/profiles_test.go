@@ -0,0 +1,84 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountProfile(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	profile, err := tokenContract.GetAccountProfile(recipientCtx, recipient)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AccountProfile{Account: recipient}, profile)
+
+	err = tokenContract.SetAccountProfile(recipientCtx, "Recipient", "https://example.com/avatar.png")
+	require.NoError(t, err)
+
+	// Anyone can read the profile, and it is only set for the calling account
+	profile, err = tokenContract.GetAccountProfile(prepMocks(stub, org1MSP, minter), recipient)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AccountProfile{Account: recipient, Name: "Recipient", AvatarURI: "https://example.com/avatar.png"}, profile)
+
+	profile, err = tokenContract.GetAccountProfile(recipientCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AccountProfile{Account: minter}, profile)
+}
+
+func TestRequireRecipientProfile(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	// By default recipients need no profile
+	_, err := tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	err = tokenContract.SetRequireRecipientProfile(prepMocks(stub, org2MSP, recipient), true)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	err = tokenContract.SetRequireRecipientProfile(minterCtx, true)
+	require.NoError(t, err)
+
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "102")
+	require.EqualError(t, err, "transfer rejected by rule profile: recipient operator has not set an account profile")
+
+	err = tokenContract.SetAccountProfile(prepMocks(stub, org2MSP, operator), "Operator", "")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "102")
+	require.NoError(t, err)
+}
+
+func TestGetAccountOverview(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetAccountProfile(minterCtx, "Minter", "https://example.com/avatar.png")
+	require.NoError(t, err)
+	err = tokenContract.ClaimHandle(minterCtx, "minter@example.com")
+	require.NoError(t, err)
+
+	overview, err := tokenContract.GetAccountOverview(prepMocks(stub, org2MSP, recipient), minter)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AccountOverview{
+		Account:    minter,
+		Registered: true,
+		Balance:    2,
+		Profile:    &chaincode.AccountProfile{Account: minter, Name: "Minter", AvatarURI: "https://example.com/avatar.png"},
+		Handle:     "minter@example.com",
+	}, overview)
+
+	// An account that never set a profile is not registered, even while it holds tokens
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	overview, err = tokenContract.GetAccountOverview(minterCtx, recipient)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AccountOverview{Account: recipient, Balance: 1}, overview)
+}
@@ -0,0 +1,353 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+)
+
+// Define objectType names for prefix
+const recoveryPrefix = "recovery"
+
+// Define key names for options
+const recoveryDelayKey = "recoveryDelay"
+
+// defaultRecoveryDelay is used until an admin configures a delay, one week in seconds
+const defaultRecoveryDelay = 7 * 24 * 60 * 60
+
+// Recovery is the recovery address an owner registered, and the state of any pending recovery
+// InitiatedAt and CompletableAt are zero while no recovery is pending
+type Recovery struct {
+	Owner         string `json:"owner"`
+	Recovery      string `json:"recovery"`
+	InitiatedAt   int64  `json:"initiatedAt"`
+	CompletableAt int64  `json:"completableAt"`
+}
+
+// SetRecoveryAddress registers the account that may reclaim all tokens of the calling owner if their key is lost
+// Registering a new address cancels any pending recovery
+func (s *SmartContract) SetRecoveryAddress(ctx contractapi.TransactionContextInterface, recovery string) error {
+
+	// Get ID of submitting client identity
+	owner, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	if recovery == "" {
+		return fmt.Errorf("recovery address must not be empty")
+	}
+	if recovery == zeroAddress {
+		return fmt.Errorf("invalid recovery address %s", recovery)
+	}
+	if recovery == owner {
+		return fmt.Errorf("recovery address must differ from the owner")
+	}
+
+	return putRecovery(ctx, &Recovery{Owner: owner, Recovery: recovery})
+}
+
+// GetRecoveryAddress returns the recovery registered by an owner
+func (s *SmartContract) GetRecoveryAddress(ctx contractapi.TransactionContextInterface, owner string) (*Recovery, error) {
+	recovery, err := readRecovery(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if recovery == nil {
+		return nil, fmt.Errorf("no recovery address is registered for %s", owner)
+	}
+
+	return recovery, nil
+}
+
+// SetRecoveryDelay sets the number of seconds between InitiateRecovery and CompleteRecovery
+// The delay gives an owner who still holds their key time to cancel an unwanted recovery
+func (s *SmartContract) SetRecoveryDelay(ctx contractapi.TransactionContextInterface, seconds int) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if seconds < 0 {
+		return fmt.Errorf("recovery delay cannot be negative")
+	}
+
+	err = ctx.GetStub().PutState(recoveryDelayKey, []byte(strconv.Itoa(seconds)))
+	if err != nil {
+		return fmt.Errorf("failed to set recovery delay: %v", err)
+	}
+
+	return nil
+}
+
+// InitiateRecovery starts the recovery delay for an owner
+// It must be submitted by the owner's registered recovery address
+// This function triggers a RecoveryInitiated event
+func (s *SmartContract) InitiateRecovery(ctx contractapi.TransactionContextInterface, owner string) error {
+	recovery, err := authorizeRecovery(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	delay := defaultRecoveryDelay
+	delayBytes, err := ctx.GetStub().GetState(recoveryDelayKey)
+	if err != nil {
+		return fmt.Errorf("failed to read recovery delay: %v", err)
+	}
+	if len(delayBytes) > 0 {
+		delay, _ = strconv.Atoi(string(delayBytes)) // Error handling not needed since Itoa() was used when setting the delay, guaranteeing it was an integer.
+	}
+
+	// The end of the delay is fixed now, so that later changes to the delay don't affect a pending recovery
+	recovery.InitiatedAt = now
+	recovery.CompletableAt = now + int64(delay)
+	err = putRecovery(ctx, recovery)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "RecoveryInitiated", events.RecoveryEvent{Owner: owner, Recovery: recovery.Recovery})
+}
+
+// CancelRecovery stops a pending recovery of the calling owner's tokens
+// This function triggers a RecoveryCancelled event
+func (s *SmartContract) CancelRecovery(ctx contractapi.TransactionContextInterface) error {
+
+	// Get ID of submitting client identity
+	owner, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	recovery, err := readRecovery(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if recovery == nil || recovery.InitiatedAt == 0 {
+		return fmt.Errorf("no recovery is pending for %s", owner)
+	}
+
+	recovery.InitiatedAt = 0
+	recovery.CompletableAt = 0
+	err = putRecovery(ctx, recovery)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "RecoveryCancelled", events.RecoveryEvent{Owner: owner, Recovery: recovery.Recovery})
+}
+
+// CompleteRecovery transfers all tokens of an owner to their recovery address once the delay has passed
+// It must be submitted by the owner's registered recovery address and returns the number of tokens recovered.
+// A lost key does not lift the constraints on the tokens: the recovery is rejected, moving nothing, if a token
+// is restricted to another organization than the recovery client's, fails a transfer rule or has reached its
+// transfer limit. The transfer callback, if one is set, is notified of every recovered token.
+// This function triggers a Recovery event listing the recovered tokens
+func (s *SmartContract) CompleteRecovery(ctx contractapi.TransactionContextInterface, owner string) (int, error) {
+	recovery, err := authorizeRecovery(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+	if recovery.InitiatedAt == 0 {
+		return 0, fmt.Errorf("no recovery is pending for %s", owner)
+	}
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if now < recovery.CompletableAt {
+		return 0, fmt.Errorf("recovery of %s cannot be completed before %d", owner, recovery.CompletableAt)
+	}
+
+	tokenIDs, err := ownedTokenIDs(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	// Check the restriction and the transfer rules of every token before moving any, and total their copies for the cap rule
+	nfts := make([]*Nft, 0, len(tokenIDs))
+	copies := make([]int, 0, len(tokenIDs))
+	incoming := 0
+	for _, tokenID := range tokenIDs {
+		nft, err := readNFT(ctx, tokenID)
+		if err != nil {
+			return 0, err
+		}
+
+		restrictedTo, err := readRestriction(ctx, tokenID)
+		if err != nil {
+			return 0, err
+		}
+		if restrictedTo != "" && restrictedTo != clientMSPID {
+			return 0, fmt.Errorf("token %s is restricted to %s and cannot be recovered by a client of %s", tokenID, restrictedTo, clientMSPID)
+		}
+
+		err = checkMove(ctx, nft, owner, recovery.Recovery)
+		if err != nil {
+			return 0, err
+		}
+
+		tokenCopies := 1
+		if nft.isEdition() {
+			tokenCopies, err = readCopies(ctx, owner, tokenID)
+			if err != nil {
+				return 0, err
+			}
+		}
+		incoming += tokenCopies
+		nfts = append(nfts, nft)
+		copies = append(copies, tokenCopies)
+	}
+
+	// The cap rule checked each token against the balance before the recovery, check their total as well
+	capEnabled, err := isTransferRuleEnabled(ctx, "cap")
+	if err != nil {
+		return 0, err
+	}
+	if capEnabled {
+		err = checkBalanceCap(ctx, recovery.Recovery, incoming)
+		if err != nil {
+			return 0, fmt.Errorf("transfer rejected by rule cap: %v", err)
+		}
+	}
+
+	for i, nft := range nfts {
+		tokenID := nft.TokenID
+
+		// Count the recovery against the limit of the token, if it has one
+		err = countTransfer(ctx, tokenID)
+		if err != nil {
+			return 0, err
+		}
+
+		if nft.isEdition() {
+			err = transferCopies(ctx, tokenID, owner, recovery.Recovery, copies[i])
+		} else {
+			// A pending offer by the owner is void once the token is recovered
+			err = deleteOffer(ctx, tokenID)
+			if err != nil {
+				return 0, err
+			}
+			err = transferHelper(ctx, nft, recovery.Recovery)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		err = appendOwnerChange(ctx, tokenID, OwnerChange{From: owner, To: recovery.Recovery})
+		if err != nil {
+			return 0, err
+		}
+		err = recordActivity(ctx, tokenID, owner, recovery.Recovery, copies[i])
+		if err != nil {
+			return 0, err
+		}
+
+		// Notify the configured callback chaincode, if any
+		err = invokeTransferCallback(ctx, owner, recovery.Recovery, tokenID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = touchTokens(ctx, tokenIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	// The recovery is used up, the owner's account no longer holds anything to recover
+	recoveryKey, err := ctx.GetStub().CreateCompositeKey(recoveryPrefix, []string{owner})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", recoveryPrefix, err)
+	}
+	err = ctx.GetStub().DelState(recoveryKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete recovery of %s: %v", owner, err)
+	}
+
+	err = emitEvent(ctx, "Recovery", events.RecoveryEvent{Owner: owner, Recovery: recovery.Recovery, TokenIDs: tokenIDs})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("%d tokens of %s recovered to %s", len(tokenIDs), owner, recovery.Recovery)
+
+	return len(tokenIDs), nil
+}
+
+// Helper Functions
+
+// authorizeRecovery returns the recovery of an owner if the client is the owner's recovery address
+func authorizeRecovery(ctx contractapi.TransactionContextInterface, owner string) (*Recovery, error) {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recovery, err := readRecovery(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if recovery == nil || recovery.Recovery != sender {
+		return nil, fmt.Errorf("client is not the recovery address of %s", owner)
+	}
+
+	return recovery, nil
+}
+
+// readRecovery returns the recovery of an owner, or nil if none was registered
+func readRecovery(ctx contractapi.TransactionContextInterface, owner string) (*Recovery, error) {
+	recoveryKey, err := ctx.GetStub().CreateCompositeKey(recoveryPrefix, []string{owner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", recoveryPrefix, err)
+	}
+	recoveryBytes, err := ctx.GetStub().GetState(recoveryKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recovery of %s: %v", owner, err)
+	}
+	if len(recoveryBytes) == 0 {
+		return nil, nil
+	}
+
+	var recovery Recovery
+	err = json.Unmarshal(recoveryBytes, &recovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recovery of %s: %v", owner, err)
+	}
+
+	return &recovery, nil
+}
+
+func putRecovery(ctx contractapi.TransactionContextInterface, recovery *Recovery) error {
+	recoveryKey, err := ctx.GetStub().CreateCompositeKey(recoveryPrefix, []string{recovery.Owner})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", recoveryPrefix, err)
+	}
+	recoveryJSON, err := json.Marshal(recovery)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(recoveryKey, recoveryJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put recovery of %s: %v", recovery.Owner, err)
+	}
+
+	return nil
+}
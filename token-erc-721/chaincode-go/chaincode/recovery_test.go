@@ -0,0 +1,178 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+const recoveryAddress = "recovery"
+
+func TestSetRecoveryAddress(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	ownerCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetRecoveryAddress(ownerCtx, minter)
+	require.EqualError(t, err, "recovery address must differ from the owner")
+
+	_, err = tokenContract.GetRecoveryAddress(ownerCtx, minter)
+	require.EqualError(t, err, "no recovery address is registered for minter")
+
+	require.NoError(t, tokenContract.SetRecoveryAddress(ownerCtx, recoveryAddress))
+	recovery, err := tokenContract.GetRecoveryAddress(ownerCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Recovery{Owner: minter, Recovery: recoveryAddress}, recovery)
+}
+
+func TestCompleteRecovery(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	ownerCtx := prepMocks(stub, org1MSP, minter)
+	recoveryCtx := prepMocks(stub, org2MSP, recoveryAddress)
+
+	require.NoError(t, tokenContract.SetRecoveryDelay(ownerCtx, 3600))
+	require.NoError(t, tokenContract.SetRecoveryAddress(ownerCtx, recoveryAddress))
+
+	// A caller other than the recovery address is rejected
+	err := tokenContract.InitiateRecovery(prepMocks(stub, org2MSP, recipient), minter)
+	require.EqualError(t, err, "client is not the recovery address of minter")
+
+	_, err = tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.EqualError(t, err, "no recovery is pending for minter")
+
+	setTxTime(stub, 1000)
+	require.NoError(t, tokenContract.InitiateRecovery(recoveryCtx, minter))
+
+	_, err = tokenContract.CompleteRecovery(prepMocks(stub, org2MSP, recipient), minter)
+	require.EqualError(t, err, "client is not the recovery address of minter")
+
+	// The delay is enforced
+	setTxTime(stub, 4599)
+	_, err = tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.EqualError(t, err, "recovery of minter cannot be completed before 4600")
+
+	setTxTime(stub, 4600)
+	drainEvents(stub)
+	recovered, err := tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 2, recovered)
+
+	balance, err := tokenContract.BalanceOf(ownerCtx, recoveryAddress)
+	require.NoError(t, err)
+	require.Equal(t, 2, balance)
+	balance, err = tokenContract.BalanceOf(ownerCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 0, balance)
+
+	events := drainEvents(stub)
+	require.Len(t, events, 1)
+	require.Equal(t, "Recovery", events[0].EventName)
+	require.JSONEq(t, `{"owner":"minter","recovery":"recovery","tokenIds":["101","102"]}`, string(events[0].Payload))
+
+	// The recovery cannot be replayed
+	_, err = tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.EqualError(t, err, "client is not the recovery address of minter")
+}
+
+func TestCompleteRecoveryConstraints(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	ownerCtx := prepMocks(stub, org1MSP, minter)
+	recoveryCtx := prepMocks(stub, org2MSP, recoveryAddress)
+
+	require.NoError(t, tokenContract.SetRecoveryDelay(ownerCtx, 0))
+	require.NoError(t, tokenContract.SetRecoveryAddress(ownerCtx, recoveryAddress))
+	require.NoError(t, tokenContract.InitiateRecovery(recoveryCtx, minter))
+
+	// A token restricted to another organization than the recovery client's cannot be recovered
+	_, err := tokenContract.MintRestricted(ownerCtx, "101", "https://example.com/nft/101", org1MSP)
+	require.NoError(t, err)
+	_, err = tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.EqualError(t, err, "token 101 is restricted to Org1MSP and cannot be recovered by a client of Org2MSP")
+	_, err = tokenContract.Burn(ownerCtx, "101")
+	require.NoError(t, err)
+
+	// A token that has used up its transfers cannot be recovered
+	_, err = tokenContract.MintWithTransferLimit(ownerCtx, "102", "https://example.com/nft/102", 2)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(ownerCtx, minter, recipient, "102")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(prepMocks(stub, org2MSP, recipient), recipient, minter, "102")
+	require.NoError(t, err)
+	_, err = tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.EqualError(t, err, "token 102 has reached its limit of 2 transfers")
+	_, err = tokenContract.Burn(ownerCtx, "102")
+	require.NoError(t, err)
+
+	// The transfer rules apply
+	mintTokens(t, stub, "103", "104")
+	require.NoError(t, tokenContract.EnableTransferRule(ownerCtx, "blocklist", true))
+	require.NoError(t, tokenContract.SetBlocklisted(ownerCtx, recoveryAddress, true))
+	_, err = tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.EqualError(t, err, "transfer rejected by rule blocklist: account recovery is blocklisted")
+	require.NoError(t, tokenContract.SetBlocklisted(ownerCtx, recoveryAddress, false))
+
+	// The balance cap applies to all recovered tokens together
+	require.NoError(t, tokenContract.EnableTransferRule(ownerCtx, "cap", true))
+	require.NoError(t, tokenContract.SetBalanceCap(ownerCtx, 1))
+	_, err = tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.EqualError(t, err, "transfer rejected by rule cap: recipient recovery would exceed the balance cap of 1")
+
+	// A token restricted to the organization of the recovery client is recovered
+	_, err = tokenContract.MintRestricted(ownerCtx, "105", "https://example.com/nft/105", org2MSP)
+	require.NoError(t, err)
+	require.NoError(t, tokenContract.SetBalanceCap(ownerCtx, 3))
+	recovered, err := tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 3, recovered)
+}
+
+func TestCompleteRecoveryCallback(t *testing.T) {
+	stub := newMockStub()
+	notifications := &notificationChaincode{}
+	stub.MockPeerChaincode("rewards", shimtest.NewMockStub("rewards", notifications), "")
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	ownerCtx := prepMocks(stub, org1MSP, minter)
+	recoveryCtx := prepMocks(stub, org2MSP, recoveryAddress)
+
+	require.NoError(t, tokenContract.SetRecoveryDelay(ownerCtx, 0))
+	require.NoError(t, tokenContract.SetRecoveryAddress(ownerCtx, recoveryAddress))
+	require.NoError(t, tokenContract.InitiateRecovery(recoveryCtx, minter))
+
+	// The callback is notified of every recovered token
+	require.NoError(t, tokenContract.SetTransferCallback(ownerCtx, "rewards", "notify", false))
+	_, err := tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{minter, recoveryAddress, "101"}, {minter, recoveryAddress, "102"}}, notifications.calls)
+}
+
+func TestCancelRecovery(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	ownerCtx := prepMocks(stub, org1MSP, minter)
+	recoveryCtx := prepMocks(stub, org2MSP, recoveryAddress)
+
+	err := tokenContract.CancelRecovery(ownerCtx)
+	require.EqualError(t, err, "no recovery is pending for minter")
+
+	require.NoError(t, tokenContract.SetRecoveryAddress(ownerCtx, recoveryAddress))
+	setTxTime(stub, 1000)
+	require.NoError(t, tokenContract.InitiateRecovery(recoveryCtx, minter))
+
+	// The default delay is one week
+	recovery, err := tokenContract.GetRecoveryAddress(ownerCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000+7*24*60*60), recovery.CompletableAt)
+
+	require.NoError(t, tokenContract.CancelRecovery(ownerCtx))
+
+	setTxTime(stub, 1000+7*24*60*60)
+	_, err = tokenContract.CompleteRecovery(recoveryCtx, minter)
+	require.EqualError(t, err, "no recovery is pending for minter")
+}
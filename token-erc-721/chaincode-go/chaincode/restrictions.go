@@ -0,0 +1,75 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const restrictionPrefix = "restriction"
+
+// MintRestricted creates a new non-fungible token that may only be held by accounts of the given organization
+// An account ID does not reveal its organization, so the recipient's MSP is only known when the recipient
+// submits the transaction. Restricted tokens therefore change hands through OfferTransfer and AcceptTransfer,
+// which checks the MSP of the accepting client, and cannot be moved with TransferFrom or TransferSale.
+// This function triggers a Transfer event
+func (s *SmartContract) MintRestricted(ctx contractapi.TransactionContextInterface, tokenID string, tokenURI string, mspID string) (*Nft, error) {
+	if mspID == "" {
+		return nil, fmt.Errorf("the organization a token is restricted to must not be empty")
+	}
+
+	nft, err := mintHelper(ctx, tokenID, tokenURI, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	restrictionKey, err := ctx.GetStub().CreateCompositeKey(restrictionPrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", restrictionPrefix, err)
+	}
+	err = ctx.GetStub().PutState(restrictionKey, []byte(mspID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to put restriction of token %s: %v", tokenID, err)
+	}
+
+	return nft, nil
+}
+
+// GetTokenRestriction returns the MSP ID of the organization a token is restricted to, empty if it circulates freely
+func (s *SmartContract) GetTokenRestriction(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	_, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	return readRestriction(ctx, tokenID)
+}
+
+// Helper Functions
+
+func readRestriction(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	restrictionKey, err := ctx.GetStub().CreateCompositeKey(restrictionPrefix, []string{tokenID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", restrictionPrefix, err)
+	}
+	restrictionBytes, err := ctx.GetStub().GetState(restrictionKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read restriction of token %s: %v", tokenID, err)
+	}
+
+	return string(restrictionBytes), nil
+}
+
+func deleteRestriction(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	restrictionKey, err := ctx.GetStub().CreateCompositeKey(restrictionPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", restrictionPrefix, err)
+	}
+	err = ctx.GetStub().DelState(restrictionKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete restriction of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
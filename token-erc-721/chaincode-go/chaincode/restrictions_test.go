@@ -0,0 +1,54 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictedToken(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.MintRestricted(minterCtx, "101", "https://example.com/nft/101", "")
+	require.EqualError(t, err, "the organization a token is restricted to must not be empty")
+
+	_, err = tokenContract.MintRestricted(minterCtx, "101", "https://example.com/nft/101", org1MSP)
+	require.NoError(t, err)
+	mintTokens(t, stub, "102")
+
+	restriction, err := tokenContract.GetTokenRestriction(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, org1MSP, restriction)
+	restriction, err = tokenContract.GetTokenRestriction(minterCtx, "102")
+	require.NoError(t, err)
+	require.Empty(t, restriction)
+
+	// A direct transfer cannot verify the organization of the recipient
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.EqualError(t, err, "token 101 is restricted to Org1MSP, transfer it with OfferTransfer")
+
+	// A recipient outside the organization cannot accept the token
+	err = tokenContract.OfferTransfer(minterCtx, recipient, "101")
+	require.NoError(t, err)
+	err = tokenContract.AcceptTransfer(prepMocks(stub, org2MSP, recipient), "101")
+	require.EqualError(t, err, "token 101 is restricted to Org1MSP and cannot be accepted by a client of Org2MSP")
+
+	// A recipient inside the organization can
+	err = tokenContract.CancelOffer(minterCtx, "101")
+	require.NoError(t, err)
+	err = tokenContract.OfferTransfer(minterCtx, operator, "101")
+	require.NoError(t, err)
+	err = tokenContract.AcceptTransfer(prepMocks(stub, org1MSP, operator), "101")
+	require.NoError(t, err)
+
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, operator, owner)
+
+	// Unrestricted tokens transfer as usual
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "102")
+	require.NoError(t, err)
+}
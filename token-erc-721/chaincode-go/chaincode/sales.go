@@ -0,0 +1,133 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+)
+
+// Define objectType names for prefix
+const lastSalePrefix = "lastSale"
+
+// Sale records a transfer that was a sale, and the price it was sold for
+type Sale struct {
+	TokenID   string `json:"tokenId"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	SalePrice int    `json:"salePrice"`
+	TxID      string `json:"txId"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// SalePrice is the price a token was last sold for, Found is false if the token was never sold
+type SalePrice struct {
+	TokenID string `json:"tokenId"`
+	Price   int    `json:"price"`
+	Found   bool   `json:"found"`
+}
+
+// TransferSale transfers a non-fungible token like TransferFrom and records the price it was sold for
+// The price is not paid through this contract, it is recorded for marketplace analytics and royalty audits.
+// This function triggers a Sale event, which carries the transfer details in place of the Transfer event
+func (s *SmartContract) TransferSale(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string, salePrice int) (bool, error) {
+	if salePrice < 0 {
+		return false, fmt.Errorf("sale price cannot be negative")
+	}
+
+	err := transferFromHelper(ctx, from, to, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	sale := Sale{
+		TokenID:   tokenID,
+		From:      from,
+		To:        to,
+		SalePrice: salePrice,
+		TxID:      ctx.GetStub().GetTxID(),
+		Timestamp: now,
+	}
+	saleKey, err := ctx.GetStub().CreateCompositeKey(lastSalePrefix, []string{tokenID})
+	if err != nil {
+		return false, fmt.Errorf("failed to create the composite key for prefix %s: %v", lastSalePrefix, err)
+	}
+	saleJSON, err := json.Marshal(sale)
+	if err != nil {
+		return false, fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(saleKey, saleJSON)
+	if err != nil {
+		return false, fmt.Errorf("failed to put sale of token %s: %v", tokenID, err)
+	}
+
+	err = appendOwnerChange(ctx, tokenID, OwnerChange{From: from, To: to, Sold: true, SalePrice: salePrice})
+	if err != nil {
+		return false, err
+	}
+	err = recordActivity(ctx, tokenID, from, to, 1)
+	if err != nil {
+		return false, err
+	}
+
+	// Emit the Sale event
+	err = emitEvent(ctx, "Sale", events.SaleEvent{From: from, To: to, TokenID: tokenID, SalePrice: salePrice})
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("token %s sold from %s to %s for %d", tokenID, from, to, salePrice)
+
+	return true, nil
+}
+
+// GetLastSalePrice returns the price of the most recent sale of a token recorded by TransferSale
+// Transfers that are not sales, such as gifts through TransferFrom, leave the last sale price unchanged.
+func (s *SmartContract) GetLastSalePrice(ctx contractapi.TransactionContextInterface, tokenID string) (*SalePrice, error) {
+	_, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	saleKey, err := ctx.GetStub().CreateCompositeKey(lastSalePrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", lastSalePrefix, err)
+	}
+	saleBytes, err := ctx.GetStub().GetState(saleKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sale of token %s: %v", tokenID, err)
+	}
+	if len(saleBytes) == 0 {
+		return &SalePrice{TokenID: tokenID}, nil
+	}
+
+	var sale Sale
+	err = json.Unmarshal(saleBytes, &sale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sale of token %s: %v", tokenID, err)
+	}
+
+	return &SalePrice{TokenID: tokenID, Price: sale.SalePrice, Found: true}, nil
+}
+
+// Helper Functions
+
+func deleteLastSale(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	saleKey, err := ctx.GetStub().CreateCompositeKey(lastSalePrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", lastSalePrefix, err)
+	}
+	err = ctx.GetStub().DelState(saleKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete sale of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferSale(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	drainEvents(stub)
+
+	_, err := tokenContract.TransferSale(minterCtx, minter, recipient, "101", -1)
+	require.EqualError(t, err, "sale price cannot be negative")
+
+	// The usual transfer checks apply
+	_, err = tokenContract.TransferSale(prepMocks(stub, org2MSP, operator), minter, recipient, "101", 250)
+	require.EqualError(t, err, "the sender is not allowed to transfer the non-fungible token")
+
+	setTxTime(stub, 1000)
+	_, err = tokenContract.TransferSale(minterCtx, minter, recipient, "101", 250)
+	require.NoError(t, err)
+
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, recipient, owner)
+
+	emitted := drainEvents(stub)
+	require.Len(t, emitted, 1)
+	require.Equal(t, "Sale", emitted[0].EventName)
+	sale, err := events.DecodeSaleEvent(emitted[0].Payload)
+	require.NoError(t, err)
+	require.Equal(t, events.SaleEvent{From: minter, To: recipient, TokenID: "101", SalePrice: 250}, sale)
+
+	// The sale is recorded with the transaction it happened in
+	saleKey, err := stub.CreateCompositeKey("lastSale", []string{"101"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"tokenId":"101","from":"minter","to":"recipient","salePrice":250,"txId":"tx1","timestamp":1000}`, string(stub.State[saleKey]))
+}
+
+func TestGetLastSalePrice(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.TransferSale(minterCtx, minter, recipient, "101", 250)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "102")
+	require.NoError(t, err)
+
+	price, err := tokenContract.GetLastSalePrice(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SalePrice{TokenID: "101", Price: 250, Found: true}, price)
+
+	price, err = tokenContract.GetLastSalePrice(minterCtx, "102")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SalePrice{TokenID: "102"}, price)
+
+	// Gifting a sold token keeps its last sale price
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+	_, err = tokenContract.TransferFrom(recipientCtx, recipient, operator, "101")
+	require.NoError(t, err)
+	price, err = tokenContract.GetLastSalePrice(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SalePrice{TokenID: "101", Price: 250, Found: true}, price)
+
+	_, err = tokenContract.GetLastSalePrice(minterCtx, "999")
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
+
+func TestLastSalePriceClearedOnBurn(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	_, err := tokenContract.TransferSale(minterCtx, minter, recipient, "101", 250)
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(recipientCtx, "101")
+	require.NoError(t, err)
+
+	// A token minted later with the same ID has never been sold
+	mintTokens(t, stub, "101")
+	price, err := tokenContract.GetLastSalePrice(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SalePrice{TokenID: "101"}, price)
+}
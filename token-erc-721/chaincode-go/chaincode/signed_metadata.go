@@ -0,0 +1,231 @@
+package chaincode
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+)
+
+// Define objectType names for prefix
+const metadataNoncePrefix = "metadataNonce"
+
+// Define key names for options
+const metadataIssuerKey = "metadataIssuer"
+
+// SetMetadataIssuer sets the PEM encoded ECDSA public key of the issuer who must co-sign UpdateTokenURISigned
+// An empty key removes the issuer, after which signed updates are rejected.
+func (s *SmartContract) SetMetadataIssuer(ctx contractapi.TransactionContextInterface, publicKeyPEM string) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if publicKeyPEM != "" {
+		_, err = parseIssuerKey([]byte(publicKeyPEM))
+		if err != nil {
+			return err
+		}
+	}
+
+	// An empty value would represent a delete, which simply removes the issuer
+	err = ctx.GetStub().PutState(metadataIssuerKey, []byte(publicKeyPEM))
+	if err != nil {
+		return fmt.Errorf("failed to set metadata issuer: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateTokenURISigned sets the URI of a token with the approval of the metadata issuer
+// It must be submitted by the owner, with issuerSignature the base64 encoded ASN.1 ECDSA signature
+// of the issuer over the SHA-256 hash of the JSON array [channelID, chaincodeName, tokenID, newURI, nonce],
+// the nonce being the decimal string returned by GetMetadataNonce. Every update increments the nonce, so a
+// signature can only be used once and an owner cannot return to a URI the issuer approved before. The channel
+// and chaincode name keep a signature from being used on another deployment that trusts the same issuer.
+// This function triggers a MetadataUpdate event
+func (s *SmartContract) UpdateTokenURISigned(ctx contractapi.TransactionContextInterface, tokenID string, newURI string, issuerSignature string) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if nft.Owner != sender {
+		return fmt.Errorf("non-fungible token %s is not owned by %s", tokenID, sender)
+	}
+
+	frozen, err := hasFlag(ctx, frozenURIPrefix, tokenID)
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return fmt.Errorf("the URI of token %s is frozen", tokenID)
+	}
+
+	issuerBytes, err := ctx.GetStub().GetState(metadataIssuerKey)
+	if err != nil {
+		return fmt.Errorf("failed to read metadata issuer: %v", err)
+	}
+	if len(issuerBytes) == 0 {
+		return fmt.Errorf("no metadata issuer is set")
+	}
+	issuerKey, err := parseIssuerKey(issuerBytes)
+	if err != nil {
+		return err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(issuerSignature)
+	if err != nil {
+		return fmt.Errorf("failed to decode issuer signature: %v", err)
+	}
+	nonce, err := readMetadataNonce(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	name, err := chaincodeName(ctx)
+	if err != nil {
+		return err
+	}
+	message, err := json.Marshal([]string{ctx.GetStub().GetChannelID(), name, tokenID, newURI, strconv.Itoa(nonce)})
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	digest := sha256.Sum256(message)
+	if !verifySignature(issuerKey, digest[:], signature) {
+		return fmt.Errorf("invalid issuer signature for the URI of token %s", tokenID)
+	}
+
+	err = setTokenURI(ctx, nft, newURI)
+	if err != nil {
+		return err
+	}
+	err = putNFT(ctx, nft)
+	if err != nil {
+		return err
+	}
+
+	// Increment the nonce to use up the signature
+	nonceKey, err := ctx.GetStub().CreateCompositeKey(metadataNoncePrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", metadataNoncePrefix, err)
+	}
+	err = ctx.GetStub().PutState(nonceKey, []byte(strconv.Itoa(nonce+1)))
+	if err != nil {
+		return fmt.Errorf("failed to put metadata nonce of token %s: %v", tokenID, err)
+	}
+
+	err = touchToken(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "MetadataUpdate", events.MetadataUpdateEvent{TokenIDs: []string{tokenID}})
+}
+
+// GetMetadataNonce returns the nonce the issuer must sign for the next UpdateTokenURISigned of a token
+func (s *SmartContract) GetMetadataNonce(ctx contractapi.TransactionContextInterface, tokenID string) (int, error) {
+	_, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return 0, err
+	}
+
+	return readMetadataNonce(ctx, tokenID)
+}
+
+// Helper Functions
+
+// readMetadataNonce returns the number of signed URI updates of a token ID
+// The nonce is kept when the token is burned, so that the signatures of a burned token cannot be
+// replayed for a token minted later with the same ID.
+func readMetadataNonce(ctx contractapi.TransactionContextInterface, tokenID string) (int, error) {
+	nonceKey, err := ctx.GetStub().CreateCompositeKey(metadataNoncePrefix, []string{tokenID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", metadataNoncePrefix, err)
+	}
+	nonceBytes, err := ctx.GetStub().GetState(nonceKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read metadata nonce of token %s: %v", tokenID, err)
+	}
+	if len(nonceBytes) == 0 {
+		return 0, nil
+	}
+
+	nonce, _ := strconv.Atoi(string(nonceBytes)) // Error handling not needed since Itoa() was used when setting the nonce, guaranteeing it was an integer.
+
+	return nonce, nil
+}
+
+// chaincodeName returns the name of the chaincode invoked by the transaction proposal
+func chaincodeName(ctx contractapi.TransactionContextInterface) (string, error) {
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil {
+		return "", fmt.Errorf("failed to get signed proposal: %v", err)
+	}
+
+	var proposal peer.Proposal
+	err = proto.Unmarshal(signedProposal.GetProposalBytes(), &proposal)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal proposal: %v", err)
+	}
+	var payload peer.ChaincodeProposalPayload
+	err = proto.Unmarshal(proposal.GetPayload(), &payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal proposal payload: %v", err)
+	}
+	var invocation peer.ChaincodeInvocationSpec
+	err = proto.Unmarshal(payload.GetInput(), &invocation)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal chaincode invocation: %v", err)
+	}
+
+	return invocation.GetChaincodeSpec().GetChaincodeId().GetName(), nil
+}
+
+// verifySignature checks an ASN.1 encoded ECDSA signature of a digest
+// The signature is decoded here rather than with ecdsa.VerifyASN1, which requires Go 1.15.
+func verifySignature(publicKey *ecdsa.PublicKey, digest []byte, signature []byte) bool {
+	var sig struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(signature, &sig)
+	if err != nil || len(rest) > 0 {
+		return false
+	}
+
+	return ecdsa.Verify(publicKey, digest, sig.R, sig.S)
+}
+
+// parseIssuerKey decodes a PEM encoded ECDSA public key
+func parseIssuerKey(publicKeyPEM []byte) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode the issuer public key, it is not PEM encoded")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the issuer public key: %v", err)
+	}
+	issuerKey, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("the issuer public key is not an ECDSA key")
+	}
+
+	return issuerKey, nil
+}
@@ -0,0 +1,133 @@
+package chaincode_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	channelID     = "mychannel"
+	chaincodeName = "token_erc721"
+)
+
+func TestUpdateTokenURISigned(t *testing.T) {
+	stub := newMockStub()
+	setDeployment(t, stub, channelID, chaincodeName)
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	issuer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signature := signURI(t, issuer, "101", "https://example.com/nft/101-v2", 0)
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v2", signature)
+	require.EqualError(t, err, "no metadata issuer is set")
+
+	err = tokenContract.SetMetadataIssuer(minterCtx, "not a key")
+	require.EqualError(t, err, "failed to decode the issuer public key, it is not PEM encoded")
+	err = tokenContract.SetMetadataIssuer(minterCtx, issuerPEM(t, issuer))
+	require.NoError(t, err)
+
+	// The signature must be the issuer's, over this token and URI
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v2", signURI(t, other, "101", "https://example.com/nft/101-v2", 0))
+	require.EqualError(t, err, "invalid issuer signature for the URI of token 101")
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v3", signature)
+	require.EqualError(t, err, "invalid issuer signature for the URI of token 101")
+
+	// Nor can a signature made for another channel or chaincode be used
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v2", signDeploymentURI(t, issuer, "otherchannel", chaincodeName, "101", "https://example.com/nft/101-v2", 0))
+	require.EqualError(t, err, "invalid issuer signature for the URI of token 101")
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v2", signDeploymentURI(t, issuer, channelID, "other_erc721", "101", "https://example.com/nft/101-v2", 0))
+	require.EqualError(t, err, "invalid issuer signature for the URI of token 101")
+
+	// Only the owner can submit the update
+	err = tokenContract.UpdateTokenURISigned(prepMocks(stub, org2MSP, recipient), "101", "https://example.com/nft/101-v2", signature)
+	require.EqualError(t, err, "non-fungible token 101 is not owned by "+recipient)
+
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v2", signature)
+	require.NoError(t, err)
+	uri, err := tokenContract.TokenURI(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/nft/101-v2", uri)
+
+	// A signature is used up by the update, so an earlier approval cannot be replayed
+	nonce, err := tokenContract.GetMetadataNonce(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, 1, nonce)
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v3", signURI(t, issuer, "101", "https://example.com/nft/101-v3", 1))
+	require.NoError(t, err)
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v2", signature)
+	require.EqualError(t, err, "invalid issuer signature for the URI of token 101")
+
+	// A frozen URI cannot be changed, even with the issuer's approval
+	err = tokenContract.FreezeTokenURI(minterCtx, "101")
+	require.NoError(t, err)
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v4", signURI(t, issuer, "101", "https://example.com/nft/101-v4", 2))
+	require.EqualError(t, err, "the URI of token 101 is frozen")
+
+	// Nor can the approvals of a burned token be replayed for a token minted later with the same ID
+	_, err = tokenContract.Burn(minterCtx, "101")
+	require.NoError(t, err)
+	mintTokens(t, stub, "101")
+	nonce, err = tokenContract.GetMetadataNonce(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, 2, nonce)
+	err = tokenContract.UpdateTokenURISigned(minterCtx, "101", "https://example.com/nft/101-v2", signature)
+	require.EqualError(t, err, "invalid issuer signature for the URI of token 101")
+}
+
+func issuerPEM(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// setDeployment sets the channel and the chaincode name of the proposal of the current transaction
+func setDeployment(t *testing.T, stub *shimtest.MockStub, channel string, name string) {
+	input, err := proto.Marshal(&peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{ChaincodeId: &peer.ChaincodeID{Name: name}}})
+	require.NoError(t, err)
+	payload, err := proto.Marshal(&peer.ChaincodeProposalPayload{Input: input})
+	require.NoError(t, err)
+	proposal, err := proto.Marshal(&peer.Proposal{Payload: payload})
+	require.NoError(t, err)
+
+	signedProposal, err := stub.GetSignedProposal()
+	require.NoError(t, err)
+	signedProposal.ProposalBytes = proposal
+	stub.ChannelID = channel
+}
+
+// signURI signs a URI update for the channel and chaincode set by setDeployment
+func signURI(t *testing.T, key *ecdsa.PrivateKey, tokenID string, uri string, nonce int) string {
+	return signDeploymentURI(t, key, channelID, chaincodeName, tokenID, uri, nonce)
+}
+
+func signDeploymentURI(t *testing.T, key *ecdsa.PrivateKey, channel string, name string, tokenID string, uri string, nonce int) string {
+	message, err := json.Marshal([]string{channel, name, tokenID, uri, strconv.Itoa(nonce)})
+	require.NoError(t, err)
+	digest := sha256.Sum256(message)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	signature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(signature)
+}
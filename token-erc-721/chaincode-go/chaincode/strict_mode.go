@@ -0,0 +1,68 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define key names for options
+const strictModeKey = "strictMode"
+
+// IsStrictMode returns whether the collection was bootstrapped in strict mode
+// Strict mode turns calls that would otherwise succeed without effect into errors: BalanceOf of an empty
+// account or of the zero address, which hold nothing by definition, and Approve of the owner itself.
+// Burn already fails with a descriptive error whenever it cannot burn, so strict mode does not change it.
+func (s *SmartContract) IsStrictMode(ctx contractapi.TransactionContextInterface) (bool, error) {
+	return isStrictMode(ctx)
+}
+
+// Helper Functions
+
+func isStrictMode(ctx contractapi.TransactionContextInterface) (bool, error) {
+	strictBytes, err := ctx.GetStub().GetState(strictModeKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read strict mode: %v", err)
+	}
+
+	return len(strictBytes) > 0, nil
+}
+
+// checkStrictBalanceOf rejects, in strict mode, a balance query of an account that cannot hold tokens
+// Dependant functions include BalanceOf
+func checkStrictBalanceOf(ctx contractapi.TransactionContextInterface, owner string) error {
+	strict, err := isStrictMode(ctx)
+	if err != nil {
+		return err
+	}
+	if !strict {
+		return nil
+	}
+
+	if owner == "" {
+		return fmt.Errorf("owner must not be empty")
+	}
+	if owner == zeroAddress {
+		return fmt.Errorf("invalid owner %s", owner)
+	}
+
+	return nil
+}
+
+// checkStrictApprove rejects, in strict mode, approving the owner of a token, which grants nothing
+// Dependant functions include approveHelper
+func checkStrictApprove(ctx contractapi.TransactionContextInterface, nft *Nft, approved string) error {
+	strict, err := isStrictMode(ctx)
+	if err != nil {
+		return err
+	}
+	if !strict {
+		return nil
+	}
+
+	if approved == nft.Owner {
+		return fmt.Errorf("%s already owns token %s and cannot be approved for it", approved, nft.TokenID)
+	}
+
+	return nil
+}
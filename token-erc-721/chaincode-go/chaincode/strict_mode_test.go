@@ -0,0 +1,50 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictMode(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	mintTokens(t, stub, "101")
+
+	// Without strict mode the legacy results are kept
+	strict, err := tokenContract.IsStrictMode(minterCtx)
+	require.NoError(t, err)
+	require.False(t, strict)
+	balance, err := tokenContract.BalanceOf(minterCtx, "")
+	require.NoError(t, err)
+	require.Equal(t, 0, balance)
+	balance, err = tokenContract.BalanceOf(minterCtx, "0x0")
+	require.NoError(t, err)
+	require.Equal(t, 0, balance)
+	_, err = tokenContract.Approve(minterCtx, minter, "101")
+	require.NoError(t, err)
+
+	err = tokenContract.Bootstrap(minterCtx, `{"name":"Collection","symbol":"COL","strictMode":true}`)
+	require.NoError(t, err)
+	strict, err = tokenContract.IsStrictMode(minterCtx)
+	require.NoError(t, err)
+	require.True(t, strict)
+	features, err := tokenContract.GetSupportedFeatures(minterCtx)
+	require.NoError(t, err)
+	require.Contains(t, features, "strictMode")
+
+	_, err = tokenContract.BalanceOf(minterCtx, "")
+	require.EqualError(t, err, "owner must not be empty")
+	_, err = tokenContract.BalanceOf(minterCtx, "0x0")
+	require.EqualError(t, err, "invalid owner 0x0")
+	balance, err = tokenContract.BalanceOf(minterCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 1, balance)
+
+	_, err = tokenContract.Approve(minterCtx, minter, "101")
+	require.EqualError(t, err, "minter already owns token 101 and cannot be approved for it")
+	_, err = tokenContract.Approve(minterCtx, operator, "101")
+	require.NoError(t, err)
+}
@@ -0,0 +1,1374 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+)
+
+// Define objectType names for prefix
+const balancePrefix = "balance"
+const nftPrefix = "nft"
+const approvalPrefix = "approval"
+const creatorPrefix = "creator"
+
+// Define key names for options
+const nameKey = "name"
+const symbolKey = "symbol"
+const totalMintedKey = "totalMinted"
+const totalBurnedKey = "totalBurned"
+const burnPolicyKey = "burnAllowOperators"
+const hashedAccountsKey = "hashedAccounts"
+
+// zeroAddress stands for no account, as the sender of a mint and the recipient of a burn
+// No real account may use it, so mints by it and transfers to it are rejected
+const zeroAddress = "0x0"
+
+// SmartContract provides functions for minting and transferring non-fungible tokens
+type SmartContract struct {
+	contractapi.Contract
+}
+
+// BalanceOf counts all non-fungible tokens assigned to an owner
+// Each copy of an edition token counts as one token. In strict mode an empty owner or the zero address is rejected.
+func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, owner string) (int, error) {
+	err := checkStrictBalanceOf(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	return balanceOf(ctx, owner)
+}
+
+// OwnerOf finds the owner of a non-fungible token
+func (s *SmartContract) OwnerOf(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+	if nft.isEdition() {
+		return "", fmt.Errorf("token %s is an edition of %d copies without a single owner, use OwnersOfEdition", tokenID, nft.Supply)
+	}
+	if nft.Owner == "" {
+		return "", fmt.Errorf("no owner is assigned to token %s", tokenID)
+	}
+
+	return nft.Owner, nil
+}
+
+// TransferFrom transfers the ownership of a non-fungible token from one owner to another owner
+// The sender must be the current owner, an authorized operator, or the approved client for this token
+// This function triggers a Transfer event
+func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) (bool, error) {
+	err := transferFromHelper(ctx, from, to, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	err = appendOwnerChange(ctx, tokenID, OwnerChange{From: from, To: to})
+	if err != nil {
+		return false, err
+	}
+	err = recordActivity(ctx, tokenID, from, to, 1)
+	if err != nil {
+		return false, err
+	}
+
+	// Emit the Transfer event
+	err = emitEvent(ctx, "Transfer", events.TransferEvent{From: from, To: to, TokenID: tokenID})
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("token %s transferred from %s to %s", tokenID, from, to)
+
+	return true, nil
+}
+
+// Approve changes or reaffirms the approved client for a non-fungible token
+// The sender must be the current owner or an authorized operator of the current owner
+// This function triggers an Approval event
+func (s *SmartContract) Approve(ctx contractapi.TransactionContextInterface, approved string, tokenID string) (bool, error) {
+	err := approveHelper(ctx, approved, tokenID, 0)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ApproveWithExpiry approves a client for a non-fungible token until the given Unix time in seconds
+// Once the transaction timestamp reaches expiresAt, the approval is treated as absent
+// This function triggers an Approval event
+func (s *SmartContract) ApproveWithExpiry(ctx contractapi.TransactionContextInterface, approved string, tokenID string, expiresAt int64) (bool, error) {
+	if expiresAt <= 0 {
+		return false, fmt.Errorf("approval expiry must be a positive Unix time")
+	}
+
+	err := approveHelper(ctx, approved, tokenID, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SetApprovalForAll enables or disables approval for a third party ("operator")
+// to manage all of the message sender's assets
+// This function triggers an ApprovalForAll event
+func (s *SmartContract) SetApprovalForAll(ctx contractapi.TransactionContextInterface, operator string, approved bool) (bool, error) {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	err = setApprovalForAllHelper(ctx, sender, []string{operator}, approved)
+	if err != nil {
+		return false, err
+	}
+
+	// Emit the ApprovalForAll event
+	err = emitEvent(ctx, "ApprovalForAll", events.ApprovalForAllEvent{Owner: sender, Operator: operator, Approved: approved})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetApproved returns the approved client for a single non-fungible token
+// An approval that has expired is reported as no approved client
+func (s *SmartContract) GetApproved(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	return currentApproved(ctx, nft)
+}
+
+// IsApprovedForAll returns if a client is an authorized operator for another client
+func (s *SmartContract) IsApprovedForAll(ctx contractapi.TransactionContextInterface, owner string, operator string) (bool, error) {
+	return isApprovedForAll(ctx, owner, operator)
+}
+
+// ============== ERC721 metadata extension ===============
+
+// Name returns a descriptive name for a collection of non-fungible tokens in this contract
+func (s *SmartContract) Name(ctx contractapi.TransactionContextInterface) (string, error) {
+	nameBytes, err := ctx.GetStub().GetState(nameKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get name: %v", err)
+	}
+
+	return string(nameBytes), nil
+}
+
+// Symbol returns an abbreviated name for non-fungible tokens in this contract
+func (s *SmartContract) Symbol(ctx contractapi.TransactionContextInterface) (string, error) {
+	symbolBytes, err := ctx.GetStub().GetState(symbolKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get symbol: %v", err)
+	}
+
+	return string(symbolBytes), nil
+}
+
+// TokenURI returns a distinct Uniform Resource Identifier (URI) for a given token
+func (s *SmartContract) TokenURI(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	return nft.TokenURI, nil
+}
+
+// ============== ERC721 enumeration extension ===============
+
+// TotalSupply counts non-fungible tokens tracked by this contract
+// There is a key record for every non-fungible token in the format of nftPrefix.tokenId.
+// TotalSupply() queries for and counts all records matching nftPrefix.*
+func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface) (int, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(nftPrefix, []string{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nft keys: %v", err)
+	}
+	defer iterator.Close()
+
+	// Count the number of returned composite keys
+	totalSupply := 0
+	for iterator.HasNext() {
+		_, err := iterator.Next()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read nft key: %v", err)
+		}
+		totalSupply++
+	}
+
+	return totalSupply, nil
+}
+
+// GetTokenCreationTx returns the ID of the transaction that minted a non-fungible token
+// Tokens minted before the transaction ID was recorded have none.
+func (s *SmartContract) GetTokenCreationTx(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+	if nft.MintTxID == "" {
+		return "", fmt.Errorf("no minting transaction is recorded for token %s", tokenID)
+	}
+
+	return nft.MintTxID, nil
+}
+
+// GetTokenFields returns only the requested fields of a stored non-fungible token, keyed by their JSON names
+// Every value is formatted as a string. Unknown field names are rejected.
+func (s *SmartContract) GetTokenFields(ctx contractapi.TransactionContextInterface, tokenID string, fields []string) (map[string]string, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(fields))
+	for _, name := range fields {
+		value, ok := nft.field(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown token field %s", name)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// TryGetToken reads a non-fungible token without treating a missing token as an error
+// The error is reserved for failures to read the ledger, so callers need not match error messages.
+func (s *SmartContract) TryGetToken(ctx contractapi.TransactionContextInterface, tokenID string) (*TokenLookup, error) {
+	nft, err := findNFT(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenLookup{TokenID: tokenID, Found: nft != nil, Token: nft}, nil
+}
+
+// GetTokensCreatedBy returns the live non-fungible tokens originally minted by a creator, whoever owns them now
+// There is a key record for every token minted in the format of creatorPrefix.creator.tokenId.
+func (s *SmartContract) GetTokensCreatedBy(ctx contractapi.TransactionContextInterface, creator string) ([]*Nft, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(creatorPrefix, []string{creator})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get creator keys for %s: %v", creator, err)
+	}
+	defer iterator.Close()
+
+	nfts := []*Nft{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read creator key: %v", err)
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key %s: %v", queryResponse.Key, err)
+		}
+
+		nft, err := readNFT(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		nfts = append(nfts, nft)
+	}
+
+	return nfts, nil
+}
+
+// GetTotalMinted counts every non-fungible token ever minted, including tokens burned since
+func (s *SmartContract) GetTotalMinted(ctx contractapi.TransactionContextInterface) (int, error) {
+	return readIntOption(ctx, totalMintedKey)
+}
+
+// GetTotalBurned counts every non-fungible token ever burned
+// TotalSupply equals GetTotalMinted minus GetTotalBurned
+func (s *SmartContract) GetTotalBurned(ctx contractapi.TransactionContextInterface) (int, error) {
+	return readIntOption(ctx, totalBurnedKey)
+}
+
+// ============== Extended Functions for this sample ===============
+
+// SetOption sets optional information for a token
+func (s *SmartContract) SetOption(ctx contractapi.TransactionContextInterface, name string, symbol string) (bool, error) {
+
+	// Check minter authorization - this sample assumes Org1 is the issuer with privilege to set the name and symbol
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return false, fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != "Org1MSP" {
+		return false, fmt.Errorf("client is not authorized to set the name and symbol of the token")
+	}
+
+	err = ctx.GetStub().PutState(nameKey, []byte(name))
+	if err != nil {
+		return false, fmt.Errorf("failed to set name: %v", err)
+	}
+	err = ctx.GetStub().PutState(symbolKey, []byte(symbol))
+	if err != nil {
+		return false, fmt.Errorf("failed to set symbol: %v", err)
+	}
+
+	return true, nil
+}
+
+// Exists returns true when a non-fungible token with the given ID has been minted and not burned
+func (s *SmartContract) Exists(ctx contractapi.TransactionContextInterface, tokenID string) (bool, error) {
+	return nftExists(ctx, tokenID)
+}
+
+// SetBurnPolicy sets whether the approved client and operators of an owner may burn the owner's tokens
+// By default only the owner can burn a token. Editions are always burned by the holder of every copy.
+func (s *SmartContract) SetBurnPolicy(ctx contractapi.TransactionContextInterface, allowOperators bool) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(burnPolicyKey, []byte(strconv.FormatBool(allowOperators)))
+	if err != nil {
+		return fmt.Errorf("failed to set burn policy: %v", err)
+	}
+
+	return nil
+}
+
+// MintWithTokenURI creates a new non-fungible token and assigns it to the minter
+// This function triggers a Transfer event
+func (s *SmartContract) MintWithTokenURI(ctx contractapi.TransactionContextInterface, tokenID string, tokenURI string) (*Nft, error) {
+	return mintHelper(ctx, tokenID, tokenURI, 1)
+}
+
+// MintWithReceipt creates a new non-fungible token like MintWithTokenURI, and returns a receipt of the mint
+// This function triggers a Transfer event
+func (s *SmartContract) MintWithReceipt(ctx contractapi.TransactionContextInterface, tokenID string, tokenURI string) (*MintReceipt, error) {
+	sequence, err := nextSequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nft, err := mintHelper(ctx, tokenID, tokenURI, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MintReceipt{TokenID: tokenID, Owner: nft.Owner, TxID: nft.MintTxID, Timestamp: now, Sequence: sequence}, nil
+}
+
+// CanMint reports whether the client could mint a non-fungible token with the given ID now, without minting it
+// Every gate of MintWithTokenURI is evaluated: authorization, the token ID, duplicates and the mint rate limit.
+// The reason a mint would be rejected is returned rather than an error, and no state is written.
+func (s *SmartContract) CanMint(ctx contractapi.TransactionContextInterface, tokenID string) (*MintCheck, error) {
+	_, err := checkMint(ctx, tokenID, 1, false)
+	if err != nil {
+		return &MintCheck{TokenID: tokenID, Allowed: false, Reason: err.Error()}, nil
+	}
+
+	return &MintCheck{TokenID: tokenID, Allowed: true}, nil
+}
+
+// Burn destroys a non-fungible token owned by the caller
+// This function triggers a Transfer event
+func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, tokenID string) (bool, error) {
+	_, err := burnHelper(ctx, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// BurnWithReceipt destroys a non-fungible token like Burn, and returns a receipt of the burn
+// Burning a token that was already burned fails with an error saying so.
+// This function triggers a Transfer event
+func (s *SmartContract) BurnWithReceipt(ctx contractapi.TransactionContextInterface, tokenID string) (*BurnReceipt, error) {
+	owner, err := burnHelper(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BurnReceipt{TokenID: tokenID, Owner: owner, TxID: ctx.GetStub().GetTxID(), Timestamp: now}, nil
+}
+
+// TransferFromWithDeadline transfers a non-fungible token like TransferFrom, unless the transaction is timestamped after deadline
+// The deadline is a Unix time in seconds. It protects a queued transfer from executing long after it was
+// signed, if its endorsement or submission is delayed.
+// This function triggers a Transfer event
+func (s *SmartContract) TransferFromWithDeadline(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string, deadline int64) (bool, error) {
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return false, err
+	}
+	if now > deadline {
+		return false, fmt.Errorf("the transfer deadline %d has passed", deadline)
+	}
+
+	return s.TransferFrom(ctx, from, to, tokenID)
+}
+
+// ClientAccountBalance returns the balance of the requesting client's account
+func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextInterface) (int, error) {
+
+	// Get ID of submitting client identity
+	clientAccountID, err := clientAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return balanceOf(ctx, clientAccountID)
+}
+
+// ClientAccountID returns the id of the requesting client's account
+// The client account ID is the clientId itself, or its SHA-256 hash once hashed accounts are enabled
+// Users can use this function to get their own account id, which they can then give to others as the payment address
+func (s *SmartContract) ClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
+
+	// Get ID of submitting client identity
+	clientAccountID, err := clientAccount(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return clientAccountID, nil
+}
+
+// ClientAccountIDHash returns the hex-encoded SHA-256 hash of the requesting client's ID
+// The hash is a compact, stable handle for the client; it is the account ID once hashed accounts are enabled
+func (s *SmartContract) ClientAccountIDHash(ctx contractapi.TransactionContextInterface) (string, error) {
+
+	// Get ID of submitting client identity
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	return hashAccountID(clientID), nil
+}
+
+// SetHashedAccounts sets whether accounts are identified by the SHA-256 hash of the client ID instead of the raw ID
+// Owners, balance keys, approvals and every other per-account record then use the hash, and so must the
+// account arguments passed to the contract. It can only be changed before the first mint, since records
+// keyed by one form of the ID are not found under the other.
+func (s *SmartContract) SetHashedAccounts(ctx contractapi.TransactionContextInterface, enabled bool) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	minted, err := readIntOption(ctx, totalMintedKey)
+	if err != nil {
+		return err
+	}
+	if minted > 0 {
+		return fmt.Errorf("hashed accounts must be configured before the first mint")
+	}
+
+	if enabled {
+		err = ctx.GetStub().PutState(hashedAccountsKey, []byte{0x00})
+	} else {
+		err = ctx.GetStub().DelState(hashedAccountsKey)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set hashed accounts: %v", err)
+	}
+
+	return nil
+}
+
+// GetClientMSP returns the MSP ID of the requesting client as seen by the peer
+// Clients can use this function to diagnose authorization failures, for example when minting
+func (s *SmartContract) GetClientMSP(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	return clientMSPID, nil
+}
+
+// GetClientIdentityAttribute returns an attribute of the requesting client's enrollment certificate
+// Found is false if the certificate does not carry the attribute
+func (s *SmartContract) GetClientIdentityAttribute(ctx contractapi.TransactionContextInterface, attrName string) (*IdentityAttribute, error) {
+	value, found, err := ctx.GetClientIdentity().GetAttributeValue(attrName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attribute %s: %v", attrName, err)
+	}
+
+	return &IdentityAttribute{Name: attrName, Value: value, Found: found}, nil
+}
+
+// Helper Functions
+
+// burnHelper destroys a non-fungible token on behalf of the caller and returns the owner it was burned from
+// Dependant functions include Burn and BurnWithReceipt
+func burnHelper(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Check if a caller is the owner of the non-fungible token
+	nft, err := findNFT(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+	if nft == nil {
+		return "", missingTokenError(ctx, tokenID)
+	}
+	owner := sender
+	if nft.isEdition() {
+		// An edition can only be burned as a whole, by a holder of every copy
+		copies, err := readCopies(ctx, owner, tokenID)
+		if err != nil {
+			return "", err
+		}
+		if copies != nft.Supply {
+			return "", fmt.Errorf("edition %s can only be burned by the holder of all %d copies", tokenID, nft.Supply)
+		}
+	} else if nft.Owner != sender {
+		// Under the burn policy, the approved client or an operator may burn on behalf of the owner
+		authorized, err := isAuthorizedBurner(ctx, nft, sender)
+		if err != nil {
+			return "", err
+		}
+		if !authorized {
+			return "", fmt.Errorf("non-fungible token %s is not owned by %s", tokenID, sender)
+		}
+		owner = nft.Owner
+	}
+
+	// Delete the token
+	nftKey, err := ctx.GetStub().CreateCompositeKey(nftPrefix, []string{tokenID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", nftPrefix, err)
+	}
+	err = ctx.GetStub().DelState(nftKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete token %s: %v", tokenID, err)
+	}
+
+	// Remove a composite key from the balance of the owner
+	balanceKey, err := ctx.GetStub().CreateCompositeKey(balancePrefix, []string{owner, tokenID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", balancePrefix, err)
+	}
+	err = ctx.GetStub().DelState(balanceKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete balance key %s: %v", balanceKey, err)
+	}
+
+	err = deleteTokenState(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	// Remove the token from the index of its creator
+	if nft.Creator != "" {
+		creatorKey, err := ctx.GetStub().CreateCompositeKey(creatorPrefix, []string{nft.Creator, tokenID})
+		if err != nil {
+			return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", creatorPrefix, err)
+		}
+		err = ctx.GetStub().DelState(creatorKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to delete creator key %s: %v", creatorKey, err)
+		}
+	}
+
+	// Remove the token from the index of its token URI
+	err = deleteTokenURIKey(ctx, nft.TokenURI, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	err = incrementCounter(ctx, totalBurnedKey)
+	if err != nil {
+		return "", err
+	}
+
+	err = appendOwnerChange(ctx, tokenID, OwnerChange{From: owner, To: zeroAddress})
+	if err != nil {
+		return "", err
+	}
+	burned := 1
+	if nft.isEdition() {
+		burned = nft.Supply
+	}
+	err = recordActivity(ctx, tokenID, owner, zeroAddress, burned)
+	if err != nil {
+		return "", err
+	}
+
+	err = touchToken(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	// Emit the Transfer event
+	err = emitEvent(ctx, "Transfer", events.TransferEvent{From: owner, To: zeroAddress, TokenID: tokenID})
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("token %s burned by %s", tokenID, sender)
+
+	return owner, nil
+}
+
+// deleteTokenState removes the state kept per token ID alongside the nft record
+// None of it must outlive the token, it would bind or describe a token minted later with the same ID.
+// Dependant functions include burnHelper
+func deleteTokenState(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	err := deleteOffer(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	err = deleteRestriction(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	err = deleteTransferLimit(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	err = deleteTokenAllowlist(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	err = deleteLastSale(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	err = setFlag(ctx, frozenURIPrefix, tokenID, false)
+	if err != nil {
+		return err
+	}
+	err = deleteLocalizedURIs(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	err = deleteTransferTime(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	err = deleteHeldSince(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	return deletePrivateMetadata(ctx, tokenID)
+}
+
+// mintHelper creates a new non-fungible token with the given number of copies and assigns it to the minter
+// Dependant functions include MintWithTokenURI and MintEdition
+func mintHelper(ctx contractapi.TransactionContextInterface, tokenID string, tokenURI string, supply int) (*Nft, error) {
+	minter, err := checkMint(ctx, tokenID, supply, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add a non-fungible token
+	nft := &Nft{
+		TokenID:  tokenID,
+		Owner:    minter,
+		Creator:  minter,
+		MintTxID: ctx.GetStub().GetTxID(),
+		TokenURI: tokenURI,
+	}
+	if supply > 1 {
+		nft.Supply = supply
+	}
+	err = putNFT(ctx, nft)
+	if err != nil {
+		return nil, err
+	}
+
+	// A composite key would be balancePrefix.owner.tokenId, which enables partial
+	// composite key query to find and count all records matching balancePrefix.owner.*
+	if nft.isEdition() {
+		err = putCopies(ctx, minter, tokenID, supply)
+	} else {
+		err = putBalanceKey(ctx, minter, tokenID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !nft.isEdition() {
+		err = recordHeldSince(ctx, tokenID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A composite key would be creatorPrefix.creator.tokenId, so that the tokens of a creator can be queried
+	creatorKey, err := ctx.GetStub().CreateCompositeKey(creatorPrefix, []string{minter, tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", creatorPrefix, err)
+	}
+	err = ctx.GetStub().PutState(creatorKey, []byte{0x00})
+	if err != nil {
+		return nil, fmt.Errorf("failed to put creator key %s: %v", creatorKey, err)
+	}
+
+	// A composite key would be tokenURIPrefix.tokenURI.tokenId, so that the tokens with a URI can be queried
+	err = putTokenURIKey(ctx, tokenURI, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = incrementCounter(ctx, totalMintedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = appendOwnerChange(ctx, tokenID, OwnerChange{From: zeroAddress, To: minter})
+	if err != nil {
+		return nil, err
+	}
+	err = recordActivity(ctx, tokenID, zeroAddress, minter, supply)
+	if err != nil {
+		return nil, err
+	}
+
+	err = touchToken(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Emit the Transfer event
+	err = emitEvent(ctx, "Transfer", events.TransferEvent{From: zeroAddress, To: minter, TokenID: tokenID})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("token %s minted to %s", tokenID, minter)
+
+	return nft, nil
+}
+
+// checkMint evaluates every gate a mint must pass and returns the minter
+// record counts the mint against the mint rate limit, CanMint passes false so that nothing is written
+func checkMint(ctx contractapi.TransactionContextInterface, tokenID string, supply int, record bool) (string, error) {
+
+	// Check minter authorization - this sample assumes Org1 is the issuer with privilege to mint a new token
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != "Org1MSP" {
+		return "", fmt.Errorf("client is not authorized to mint new tokens")
+	}
+
+	// Get ID of submitting client identity
+	minter, err := clientAccount(ctx)
+	if err != nil {
+		return "", err
+	}
+	if minter == zeroAddress {
+		return "", fmt.Errorf("invalid minter %s", minter)
+	}
+
+	// Check if the token to be minted does not exist
+	// A missing token is not an error here, unlike readNFT, so only a minted token is reported as a duplicate
+	exists, err := nftExists(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("the token %s is already minted", tokenID)
+	}
+
+	if _, err := strconv.Atoi(tokenID); err != nil {
+		return "", fmt.Errorf("the tokenId %s is invalid. tokenId must be an integer", tokenID)
+	}
+
+	if supply < 1 {
+		return "", fmt.Errorf("supply must be a positive integer")
+	}
+
+	err = checkMintRateLimit(ctx, record)
+	if err != nil {
+		return "", err
+	}
+
+	return minter, nil
+}
+
+// transferFromHelper authorizes the sender, applies the transfer checks and moves a token, without emitting an event
+// Dependant functions include TransferFrom and TransferSale
+func transferFromHelper(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	if nft.isEdition() {
+		// Copies of an edition have no approved client, only holders and their operators can move them
+		operatorApproval, err := isApprovedForAll(ctx, from, sender)
+		if err != nil {
+			return err
+		}
+		if from != sender && !operatorApproval {
+			return fmt.Errorf("the sender is not allowed to transfer the non-fungible token")
+		}
+
+		// Check if from holds a copy of the edition
+		copies, err := readCopies(ctx, from, tokenID)
+		if err != nil {
+			return err
+		}
+		if copies == 0 {
+			return fmt.Errorf("the from holds no copy of edition %s", tokenID)
+		}
+	} else {
+		// A token left without an owner by corrupted state must not be claimed by a transfer from ""
+		if nft.Owner == "" {
+			return fmt.Errorf("no owner is assigned to token %s", tokenID)
+		}
+
+		// Check if the sender is the current owner, an authorized operator,
+		// or the approved client for this non-fungible token.
+		owner := nft.Owner
+		operatorApproval, err := isApprovedForAll(ctx, owner, sender)
+		if err != nil {
+			return err
+		}
+		approved, err := currentApproved(ctx, nft)
+		if err != nil {
+			return err
+		}
+		if owner != sender && approved != sender && !operatorApproval {
+			return fmt.Errorf("the sender is not allowed to transfer the non-fungible token")
+		}
+
+		// Check if from is the current owner
+		if owner != from {
+			return fmt.Errorf("the from is not the current owner")
+		}
+
+		// A token offered to a recipient can only move to them, through AcceptTransfer
+		offer, err := readOffer(ctx, tokenID)
+		if err != nil {
+			return err
+		}
+		if offer != nil {
+			return fmt.Errorf("token %s has a pending transfer offer to %s", tokenID, offer.To)
+		}
+
+		// The organization of the recipient can only be verified when the recipient accepts an offer
+		restrictedTo, err := readRestriction(ctx, tokenID)
+		if err != nil {
+			return err
+		}
+		if restrictedTo != "" {
+			return fmt.Errorf("token %s is restricted to %s, transfer it with OfferTransfer", tokenID, restrictedTo)
+		}
+	}
+
+	return moveToken(ctx, nft, from, to)
+}
+
+// moveToken applies the transfer rules and moves a token, or a single copy of an edition, after the sender was authorized
+// Dependant functions include transferFromHelper and AcceptTransfer
+func moveToken(ctx contractapi.TransactionContextInterface, nft *Nft, from string, to string) error {
+	err := checkMove(ctx, nft, from, to)
+	if err != nil {
+		return err
+	}
+
+	err = applyMove(ctx, nft, from, to)
+	if err != nil {
+		return err
+	}
+
+	err = touchToken(ctx, nft.TokenID)
+	if err != nil {
+		return err
+	}
+
+	// Notify the configured callback chaincode, if any
+	return invokeTransferCallback(ctx, from, to, nft.TokenID)
+}
+
+// checkMove rejects a move of a token to the zero address or against the enabled transfer rules
+func checkMove(ctx contractapi.TransactionContextInterface, nft *Nft, from string, to string) error {
+	if to == zeroAddress {
+		return fmt.Errorf("invalid recipient %s", to)
+	}
+
+	// Check the enabled transfer rules
+	return applyTransferRules(ctx, from, to, nft.TokenID)
+}
+
+// applyMove moves a token, or a single copy of an edition, once checkMove passed
+// The modification index is not touched, so that a batch can touch all of its tokens in one counter update
+func applyMove(ctx contractapi.TransactionContextInterface, nft *Nft, from string, to string) error {
+
+	// Count the transfer against the limit of the token, if it has one
+	err := countTransfer(ctx, nft.TokenID)
+	if err != nil {
+		return err
+	}
+
+	// Initiate the transfer, of a single copy for an edition
+	if nft.isEdition() {
+		return transferCopies(ctx, nft.TokenID, from, to, 1)
+	}
+
+	return transferHelper(ctx, nft, to)
+}
+
+// approveHelper sets the approved client of a non-fungible token with an optional expiry, zero for none
+// Dependant functions include Approve and ApproveWithExpiry
+func approveHelper(ctx contractapi.TransactionContextInterface, approved string, tokenID string, expiresAt int64) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if nft.isEdition() {
+		return fmt.Errorf("token %s is an edition, approve an operator with SetApprovalForAll instead", tokenID)
+	}
+	if nft.Owner == "" {
+		return fmt.Errorf("no owner is assigned to token %s", tokenID)
+	}
+
+	// Check if the sender is the current owner of the non-fungible token
+	// or an authorized operator of the current owner
+	owner := nft.Owner
+	operatorApproval, err := isApprovedForAll(ctx, owner, sender)
+	if err != nil {
+		return err
+	}
+	if owner != sender && !operatorApproval {
+		return fmt.Errorf("the sender is not the current owner nor an authorized operator")
+	}
+	err = checkStrictApprove(ctx, nft, approved)
+	if err != nil {
+		return err
+	}
+
+	// Update the approved client of the non-fungible token
+	nft.Approved = approved
+	nft.ApprovedUntil = expiresAt
+	err = putNFT(ctx, nft)
+	if err != nil {
+		return err
+	}
+
+	err = touchToken(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	// Emit the Approval event
+	return emitEvent(ctx, "Approval", events.ApprovalEvent{Owner: owner, Approved: approved, TokenID: tokenID})
+}
+
+// isAuthorizedBurner returns true if the burn policy lets the sender burn a token of another owner
+func isAuthorizedBurner(ctx contractapi.TransactionContextInterface, nft *Nft, sender string) (bool, error) {
+	policyBytes, err := ctx.GetStub().GetState(burnPolicyKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read burn policy: %v", err)
+	}
+	allowOperators, _ := strconv.ParseBool(string(policyBytes)) // Error handling not needed since FormatBool() was used when setting the policy, and an unset policy parses as false.
+	if !allowOperators {
+		return false, nil
+	}
+
+	approved, err := currentApproved(ctx, nft)
+	if err != nil {
+		return false, err
+	}
+	if approved == sender {
+		return true, nil
+	}
+
+	return isApprovedForAll(ctx, nft.Owner, sender)
+}
+
+// currentApproved returns the approved client of a non-fungible token, or "" if its approval has expired
+func currentApproved(ctx contractapi.TransactionContextInterface, nft *Nft) (string, error) {
+	if nft.ApprovedUntil == 0 {
+		return nft.Approved, nil
+	}
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return "", err
+	}
+	if now >= nft.ApprovedUntil {
+		return "", nil
+	}
+
+	return nft.Approved, nil
+}
+
+// isApprovedForAll returns if a client is an authorized operator for another client
+func isApprovedForAll(ctx contractapi.TransactionContextInterface, owner string, operator string) (bool, error) {
+	approvalKey, err := ctx.GetStub().CreateCompositeKey(approvalPrefix, []string{owner, operator})
+	if err != nil {
+		return false, fmt.Errorf("failed to create the composite key for prefix %s: %v", approvalPrefix, err)
+	}
+	approvalBytes, err := ctx.GetStub().GetState(approvalKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read approval %s from world state: %v", approvalKey, err)
+	}
+
+	// If no approval was ever recorded, the operator is not approved
+	if len(approvalBytes) == 0 {
+		return false, nil
+	}
+
+	var approval Approval
+	err = json.Unmarshal(approvalBytes, &approval)
+	if err != nil {
+		return false, fmt.Errorf("failed to unmarshal approval %s: %v", approvalKey, err)
+	}
+
+	return approval.Approved, nil
+}
+
+// balanceOf counts all non-fungible tokens assigned to an owner
+// There is a key record for every non-fungible token in the format of balancePrefix.owner.tokenId.
+// BalanceOf() queries for and counts all records matching balancePrefix.owner.*
+// Each copy of an edition token counts as one token.
+func balanceOf(ctx contractapi.TransactionContextInterface, owner string) (int, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(balancePrefix, []string{owner})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balance keys for owner %s: %v", owner, err)
+	}
+	defer iterator.Close()
+
+	// Count the number of returned composite keys, and the number of copies held of editions
+	balance := 0
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read balance key: %v", err)
+		}
+		balance += parseCopies(queryResponse.Value)
+	}
+
+	return balance, nil
+}
+
+// setApprovalForAllHelper approves or removes operators of an owner and records the changes in the approval log
+// Dependant functions include SetApprovalForAll and SetApprovalForAllBatch
+func setApprovalForAllHelper(ctx contractapi.TransactionContextInterface, owner string, operators []string, approved bool) error {
+	for _, operator := range operators {
+		approval := Approval{Owner: owner, Operator: operator, Approved: approved}
+		approvalKey, err := ctx.GetStub().CreateCompositeKey(approvalPrefix, []string{owner, operator})
+		if err != nil {
+			return fmt.Errorf("failed to create the composite key for prefix %s: %v", approvalPrefix, err)
+		}
+		approvalJSON, err := json.Marshal(approval)
+		if err != nil {
+			return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		}
+		err = ctx.GetStub().PutState(approvalKey, approvalJSON)
+		if err != nil {
+			return fmt.Errorf("failed to put approval %s: %v", approvalKey, err)
+		}
+	}
+
+	return appendApprovalLog(ctx, owner, operators, approved)
+}
+
+// transferHelper assigns a non-fungible token to a new owner and moves it between the owners' balances
+// Dependant functions include TransferFrom and CompleteRecovery
+func transferHelper(ctx contractapi.TransactionContextInterface, nft *Nft, to string) error {
+	from := nft.Owner
+	tokenID := nft.TokenID
+
+	// Clear the approved client for this non-fungible token
+	nft.Approved = ""
+	nft.ApprovedUntil = 0
+
+	// Overwrite a non-fungible token to assign a new owner
+	nft.Owner = to
+	err := putNFT(ctx, nft)
+	if err != nil {
+		return err
+	}
+
+	// Remove a composite key from the balance of the current owner
+	balanceKeyFrom, err := ctx.GetStub().CreateCompositeKey(balancePrefix, []string{from, tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", balancePrefix, err)
+	}
+	err = ctx.GetStub().DelState(balanceKeyFrom)
+	if err != nil {
+		return fmt.Errorf("failed to delete balance key %s: %v", balanceKeyFrom, err)
+	}
+
+	// Save a composite key to count the balance of a new owner
+	err = putBalanceKey(ctx, to, tokenID)
+	if err != nil {
+		return err
+	}
+
+	// Record when the token changed hands for the cooldown rule and holding duration
+	err = recordTransferTime(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	return recordHeldSince(ctx, tokenID)
+}
+
+// readNFT reads a non-fungible token from the world state
+func readNFT(ctx contractapi.TransactionContextInterface, tokenID string) (*Nft, error) {
+	nft, err := findNFT(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if nft == nil {
+		return nil, fmt.Errorf("the tokenId %s is invalid. It does not exist", tokenID)
+	}
+
+	return nft, nil
+}
+
+// missingTokenError explains why a token does not exist, telling a burned token apart from one never minted
+func missingTokenError(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	changes, err := readOwnerChanges(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 && changes[len(changes)-1].To == zeroAddress {
+		return fmt.Errorf("token %s was already burned", tokenID)
+	}
+
+	return fmt.Errorf("the tokenId %s is invalid. It does not exist", tokenID)
+}
+
+// findNFT reads a non-fungible token, returning nil if it does not exist
+func findNFT(ctx contractapi.TransactionContextInterface, tokenID string) (*Nft, error) {
+	nftKey, err := ctx.GetStub().CreateCompositeKey(nftPrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", nftPrefix, err)
+	}
+
+	nftBytes, err := ctx.GetStub().GetState(nftKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token %s from world state: %v", tokenID, err)
+	}
+	if len(nftBytes) == 0 {
+		return nil, nil
+	}
+
+	var nft Nft
+	err = json.Unmarshal(nftBytes, &nft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token %s: %v", tokenID, err)
+	}
+
+	return &nft, nil
+}
+
+// putNFT writes a non-fungible token to the world state
+func putNFT(ctx contractapi.TransactionContextInterface, nft *Nft) error {
+	nftKey, err := ctx.GetStub().CreateCompositeKey(nftPrefix, []string{nft.TokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", nftPrefix, err)
+	}
+
+	nftJSON, err := json.Marshal(nft)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(nftKey, nftJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put token %s: %v", nft.TokenID, err)
+	}
+
+	return nil
+}
+
+// nftExists returns true when a non-fungible token with the given ID exists in the world state
+func nftExists(ctx contractapi.TransactionContextInterface, tokenID string) (bool, error) {
+	nftKey, err := ctx.GetStub().CreateCompositeKey(nftPrefix, []string{tokenID})
+	if err != nil {
+		return false, fmt.Errorf("failed to create the composite key for prefix %s: %v", nftPrefix, err)
+	}
+
+	nftBytes, err := ctx.GetStub().GetState(nftKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read token %s from world state: %v", tokenID, err)
+	}
+
+	return len(nftBytes) > 0, nil
+}
+
+// putBalanceKey records one token in the balance of an owner
+// An empty value would represent a delete, so we simply insert the null character
+func putBalanceKey(ctx contractapi.TransactionContextInterface, owner string, tokenID string) error {
+	balanceKey, err := ctx.GetStub().CreateCompositeKey(balancePrefix, []string{owner, tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", balancePrefix, err)
+	}
+
+	err = ctx.GetStub().PutState(balanceKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put balance key %s: %v", balanceKey, err)
+	}
+
+	return nil
+}
+
+// clientAccount returns the account ID of the submitting client, used wherever the client is an owner, sender or operator
+func clientAccount(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	hashedBytes, err := ctx.GetStub().GetState(hashedAccountsKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read hashed accounts: %v", err)
+	}
+	if len(hashedBytes) > 0 {
+		return hashAccountID(clientID), nil
+	}
+
+	return clientID, nil
+}
+
+func hashAccountID(clientID string) string {
+	hash := sha256.Sum256([]byte(clientID))
+	return hex.EncodeToString(hash[:])
+}
+
+// authorizeAdmin checks that the client may perform administrative functions
+// This sample assumes Org1 is the issuer with the administrative privilege
+func authorizeAdmin(ctx contractapi.TransactionContextInterface) error {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != "Org1MSP" {
+		return fmt.Errorf("client is not authorized to perform admin functions")
+	}
+
+	return nil
+}
+
+// txTimestamp returns the transaction timestamp in seconds since the Unix epoch
+// The timestamp is chosen by the client and is the same on every endorsing peer, which keeps endorsements deterministic
+func txTimestamp(ctx contractapi.TransactionContextInterface) (int64, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	return timestamp.Seconds, nil
+}
+
+// ownedTokenIDs returns the IDs of all tokens in the balance of an owner
+func ownedTokenIDs(ctx contractapi.TransactionContextInterface, owner string) ([]string, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(balancePrefix, []string{owner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance keys for owner %s: %v", owner, err)
+	}
+	defer iterator.Close()
+
+	tokenIDs := []string{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read balance key: %v", err)
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key %s: %v", queryResponse.Key, err)
+		}
+		tokenIDs = append(tokenIDs, attributes[1])
+	}
+
+	return tokenIDs, nil
+}
+
+// incrementCounter adds one to an integer option
+// Like touchToken, it must be called at most once per counter and transaction
+func incrementCounter(ctx contractapi.TransactionContextInterface, key string) error {
+	count, err := readIntOption(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(key, []byte(strconv.Itoa(count+1)))
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %v", key, err)
+	}
+
+	return nil
+}
+
+// emitEvent marshals the event payload and sets it on the transaction, unless the event was turned off
+// Only the last event set in a transaction is delivered, so a function that changes several tokens or
+// operators emits one event listing all of them.
+func emitEvent(ctx contractapi.TransactionContextInterface, eventName string, event interface{}) error {
+	disabled, err := hasFlag(ctx, eventDisabledPrefix, eventName)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		return nil
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent(eventName, eventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,947 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+These unit tests run the contract against the shimtest MockStub, which keeps an in-memory world state,
+while the transaction context and client identity are counterfeiter mocks.
+All files in mocks/* are generated by running following, in the directory with your directive:
+	`go generate`
+*/
+
+//go:generate counterfeiter -o mocks/transaction.go -fake-name TransactionContext . transactionContext
+type transactionContext interface {
+	contractapi.TransactionContextInterface
+}
+
+//go:generate counterfeiter -o mocks/chaincodestub.go -fake-name ChaincodeStub . chaincodeStub
+type chaincodeStub interface {
+	shim.ChaincodeStubInterface
+}
+
+//go:generate counterfeiter -o mocks/statequeryiterator.go -fake-name StateQueryIterator . stateQueryIterator
+type stateQueryIterator interface {
+	shim.StateQueryIteratorInterface
+}
+
+//go:generate counterfeiter -o mocks/clientIdentity.go -fake-name ClientIdentity . clientIdentity
+type clientIdentity interface {
+	cid.ClientIdentity
+}
+
+const org1MSP = "Org1MSP"
+const org2MSP = "Org2MSP"
+const minter = "minter"
+const recipient = "recipient"
+const operator = "operator"
+
+// newMockStub returns an in-memory stub with a transaction already started
+func newMockStub() *shimtest.MockStub {
+	stub := shimtest.NewMockStub("token_erc721", nil)
+	stub.ChaincodeEventsChannel = make(chan *peer.ChaincodeEvent, 1000)
+	stub.MockTransactionStart("tx1")
+	return stub
+}
+
+// setTxTime sets the timestamp of the current transaction in seconds since the Unix epoch
+func setTxTime(stub *shimtest.MockStub, seconds int64) {
+	stub.TxTimestamp = &timestamp.Timestamp{Seconds: seconds}
+}
+
+// prepMocks returns a transaction context for the given client identity backed by the given stub
+func prepMocks(stub shim.ChaincodeStubInterface, orgMSP, clientID string) *mocks.TransactionContext {
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(stub)
+
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetMSPIDReturns(orgMSP, nil)
+	clientIdentity.GetIDReturns(clientID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
+
+	return transactionContext
+}
+
+// mintTokens mints the given token IDs to the minter
+func mintTokens(t *testing.T, stub *shimtest.MockStub, tokenIDs ...string) {
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	tokenContract := chaincode.SmartContract{}
+	for _, tokenID := range tokenIDs {
+		_, err := tokenContract.MintWithTokenURI(minterCtx, tokenID, "https://example.com/nft/"+tokenID)
+		require.NoError(t, err)
+	}
+}
+
+// drainEvents returns all events emitted on the stub since the last call
+func drainEvents(stub *shimtest.MockStub) []*peer.ChaincodeEvent {
+	var events []*peer.ChaincodeEvent
+	for {
+		select {
+		case event := <-stub.ChaincodeEventsChannel:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestNewChaincode(t *testing.T) {
+	_, err := contractapi.NewChaincode(&chaincode.SmartContract{})
+	require.NoError(t, err)
+}
+
+func TestMintWithTokenURI(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+
+	nft, err := tokenContract.MintWithTokenURI(prepMocks(stub, org1MSP, minter), "101", "https://example.com/nft/101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Nft{TokenID: "101", Owner: minter, Creator: minter, MintTxID: "tx1", TokenURI: "https://example.com/nft/101"}, nft)
+
+	events := drainEvents(stub)
+	require.Len(t, events, 1)
+	require.Equal(t, "Transfer", events[0].EventName)
+	require.JSONEq(t, `{"from":"0x0","to":"minter","tokenId":"101"}`, string(events[0].Payload))
+
+	_, err = tokenContract.MintWithTokenURI(prepMocks(stub, org2MSP, recipient), "102", "")
+	require.EqualError(t, err, "client is not authorized to mint new tokens")
+
+	_, err = tokenContract.MintWithTokenURI(prepMocks(stub, org1MSP, minter), "abc", "")
+	require.EqualError(t, err, "the tokenId abc is invalid. tokenId must be an integer")
+}
+
+func TestMintDuplicate(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	exists, err := tokenContract.Exists(minterCtx, "101")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "101", "")
+	require.NoError(t, err)
+
+	exists, err = tokenContract.Exists(minterCtx, "101")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "101", "")
+	require.EqualError(t, err, "the token 101 is already minted")
+
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "102", "")
+	require.NoError(t, err)
+
+	// A burned token no longer exists
+	_, err = tokenContract.Burn(minterCtx, "102")
+	require.NoError(t, err)
+	exists, err = tokenContract.Exists(minterCtx, "102")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestOwnerOfAndBalanceOf(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	ctx := prepMocks(stub, org2MSP, recipient)
+	tokenContract := chaincode.SmartContract{}
+
+	owner, err := tokenContract.OwnerOf(ctx, "101")
+	require.NoError(t, err)
+	require.Equal(t, minter, owner)
+
+	_, err = tokenContract.OwnerOf(ctx, "103")
+	require.EqualError(t, err, "the tokenId 103 is invalid. It does not exist")
+
+	balance, err := tokenContract.BalanceOf(ctx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 2, balance)
+
+	balance, err = tokenContract.ClientAccountBalance(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, balance)
+
+	totalSupply, err := tokenContract.TotalSupply(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, totalSupply)
+}
+
+func TestTransferFrom(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	drainEvents(stub)
+	tokenContract := chaincode.SmartContract{}
+
+	_, err := tokenContract.TransferFrom(prepMocks(stub, org2MSP, recipient), minter, recipient, "101")
+	require.EqualError(t, err, "the sender is not allowed to transfer the non-fungible token")
+
+	_, err = tokenContract.TransferFrom(prepMocks(stub, org1MSP, minter), recipient, minter, "101")
+	require.EqualError(t, err, "the from is not the current owner")
+
+	ok, err := tokenContract.TransferFrom(prepMocks(stub, org1MSP, minter), minter, recipient, "101")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	owner, err := tokenContract.OwnerOf(prepMocks(stub, org1MSP, minter), "101")
+	require.NoError(t, err)
+	require.Equal(t, recipient, owner)
+
+	minterBalance, err := tokenContract.BalanceOf(prepMocks(stub, org1MSP, minter), minter)
+	require.NoError(t, err)
+	require.Equal(t, 0, minterBalance)
+	recipientBalance, err := tokenContract.BalanceOf(prepMocks(stub, org1MSP, minter), recipient)
+	require.NoError(t, err)
+	require.Equal(t, 1, recipientBalance)
+
+	events := drainEvents(stub)
+	require.Len(t, events, 1)
+	require.Equal(t, "Transfer", events[0].EventName)
+	require.JSONEq(t, `{"from":"minter","to":"recipient","tokenId":"101"}`, string(events[0].Payload))
+}
+
+func TestTransferFromWithDeadline(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	setTxTime(stub, 2001)
+	_, err := tokenContract.TransferFromWithDeadline(minterCtx, minter, recipient, "101", 2000)
+	require.EqualError(t, err, "the transfer deadline 2000 has passed")
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, minter, owner)
+
+	// A transfer at the deadline is still in time
+	setTxTime(stub, 2000)
+	ok, err := tokenContract.TransferFromWithDeadline(minterCtx, minter, recipient, "101", 2000)
+	require.NoError(t, err)
+	require.True(t, ok)
+	owner, err = tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, recipient, owner)
+}
+
+func TestApprove(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+
+	_, err := tokenContract.Approve(prepMocks(stub, org2MSP, recipient), recipient, "101")
+	require.EqualError(t, err, "the sender is not the current owner nor an authorized operator")
+
+	_, err = tokenContract.Approve(prepMocks(stub, org1MSP, minter), operator, "101")
+	require.NoError(t, err)
+
+	approved, err := tokenContract.GetApproved(prepMocks(stub, org1MSP, minter), "101")
+	require.NoError(t, err)
+	require.Equal(t, operator, approved)
+
+	// The approved client can transfer the token, which clears the approval
+	_, err = tokenContract.TransferFrom(prepMocks(stub, org2MSP, operator), minter, recipient, "101")
+	require.NoError(t, err)
+
+	approved, err = tokenContract.GetApproved(prepMocks(stub, org1MSP, minter), "101")
+	require.NoError(t, err)
+	require.Equal(t, "", approved)
+}
+
+func TestApproveWithExpiry(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	operatorCtx := prepMocks(stub, org2MSP, operator)
+
+	setTxTime(stub, 1000)
+	_, err := tokenContract.ApproveWithExpiry(minterCtx, operator, "101", 0)
+	require.EqualError(t, err, "approval expiry must be a positive Unix time")
+
+	_, err = tokenContract.ApproveWithExpiry(minterCtx, operator, "101", 2000)
+	require.NoError(t, err)
+	_, err = tokenContract.ApproveWithExpiry(minterCtx, operator, "102", 2000)
+	require.NoError(t, err)
+
+	// Before the expiry the approved client can transfer the token
+	setTxTime(stub, 1999)
+	approved, err := tokenContract.GetApproved(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, operator, approved)
+	_, err = tokenContract.TransferFrom(operatorCtx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	// From the expiry on the approval is treated as absent
+	setTxTime(stub, 2000)
+	approved, err = tokenContract.GetApproved(minterCtx, "102")
+	require.NoError(t, err)
+	require.Equal(t, "", approved)
+	_, err = tokenContract.TransferFrom(operatorCtx, minter, recipient, "102")
+	require.EqualError(t, err, "the sender is not allowed to transfer the non-fungible token")
+
+	// A plain approval replaces the expiring one and does not expire
+	_, err = tokenContract.Approve(minterCtx, operator, "102")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(operatorCtx, minter, recipient, "102")
+	require.NoError(t, err)
+}
+
+func TestEventPayloadsDecode(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	drainEvents(stub)
+
+	_, err := tokenContract.Approve(minterCtx, operator, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.SetApprovalForAll(minterCtx, operator, true)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	emitted := drainEvents(stub)
+	require.Len(t, emitted, 3)
+
+	approval, err := events.DecodeApprovalEvent(emitted[0].Payload)
+	require.NoError(t, err)
+	require.Equal(t, events.ApprovalEvent{Owner: minter, Approved: operator, TokenID: "101"}, approval)
+
+	approvalForAll, err := events.DecodeApprovalForAllEvent(emitted[1].Payload)
+	require.NoError(t, err)
+	require.Equal(t, events.ApprovalForAllEvent{Owner: minter, Operator: operator, Approved: true}, approvalForAll)
+
+	transfer, err := events.DecodeTransferEvent(emitted[2].Payload)
+	require.NoError(t, err)
+	require.Equal(t, events.TransferEvent{From: minter, To: recipient, TokenID: "101"}, transfer)
+}
+
+func TestSetApprovalForAll(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	drainEvents(stub)
+	tokenContract := chaincode.SmartContract{}
+
+	approved, err := tokenContract.IsApprovedForAll(prepMocks(stub, org1MSP, minter), minter, operator)
+	require.NoError(t, err)
+	require.False(t, approved)
+
+	_, err = tokenContract.SetApprovalForAll(prepMocks(stub, org1MSP, minter), operator, true)
+	require.NoError(t, err)
+
+	approved, err = tokenContract.IsApprovedForAll(prepMocks(stub, org1MSP, minter), minter, operator)
+	require.NoError(t, err)
+	require.True(t, approved)
+
+	events := drainEvents(stub)
+	require.Len(t, events, 1)
+	var approvalForAll map[string]interface{}
+	require.NoError(t, json.Unmarshal(events[0].Payload, &approvalForAll))
+	require.Equal(t, "ApprovalForAll", events[0].EventName)
+	require.Equal(t, true, approvalForAll["approved"])
+
+	// The operator can transfer any token of the owner
+	_, err = tokenContract.TransferFrom(prepMocks(stub, org2MSP, operator), minter, recipient, "101")
+	require.NoError(t, err)
+}
+
+func TestBurn(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+
+	_, err := tokenContract.Burn(prepMocks(stub, org2MSP, recipient), "101")
+	require.EqualError(t, err, "non-fungible token 101 is not owned by recipient")
+
+	_, err = tokenContract.Burn(prepMocks(stub, org1MSP, minter), "101")
+	require.NoError(t, err)
+
+	totalSupply, err := tokenContract.TotalSupply(prepMocks(stub, org1MSP, minter))
+	require.NoError(t, err)
+	require.Equal(t, 0, totalSupply)
+
+	balance, err := tokenContract.BalanceOf(prepMocks(stub, org1MSP, minter), minter)
+	require.NoError(t, err)
+	require.Equal(t, 0, balance)
+}
+
+// privateDataStub completes the MockStub, which does not implement the deletion of private data
+type privateDataStub struct {
+	*shimtest.MockStub
+}
+
+func (stub privateDataStub) DelPrivateData(collection string, key string) error {
+	delete(stub.PvtState[collection], key)
+	return nil
+}
+
+func TestBurnClearsTokenState(t *testing.T) {
+	os.Setenv("CORE_PEER_LOCALMSPID", org1MSP)
+	defer os.Unsetenv("CORE_PEER_LOCALMSPID")
+
+	mockStub := newMockStub()
+	stub := privateDataStub{mockStub}
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+	setTxTime(mockStub, 1000)
+
+	// Token 101 collects every kind of state its owners and the admin can attach to it
+	mockStub.TransientMap = map[string][]byte{
+		"private_metadata": []byte(`{"collection":"Org1MSPPrivateCollection","fields":{"holderName":"Alice"}}`),
+	}
+	_, err := tokenContract.MintWithPrivateMetadata(minterCtx, "101", "https://example.com/nft/101")
+	require.NoError(t, err)
+	err = tokenContract.SetLocalizedURI(minterCtx, "101", "fr", "https://example.com/fr/nft/101")
+	require.NoError(t, err)
+	err = tokenContract.SetTokenTransferAllowlist(minterCtx, "101", []string{recipient})
+	require.NoError(t, err)
+	err = tokenContract.EnableTransferRule(minterCtx, "cooldown", true)
+	require.NoError(t, err)
+	err = tokenContract.SetTransferCooldown(minterCtx, 100)
+	require.NoError(t, err)
+	_, err = tokenContract.TransferSale(minterCtx, minter, recipient, "101", 250)
+	require.NoError(t, err)
+	err = tokenContract.FreezeTokenURI(minterCtx, "101")
+	require.NoError(t, err)
+	err = tokenContract.OfferTransfer(recipientCtx, operator, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(recipientCtx, "101")
+	require.NoError(t, err)
+
+	// Restrictions and transfer limits are set when minting
+	_, err = tokenContract.MintRestricted(minterCtx, "102", "https://example.com/nft/102", org1MSP)
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(minterCtx, "102")
+	require.NoError(t, err)
+	_, err = tokenContract.MintWithTransferLimit(minterCtx, "103", "https://example.com/nft/103", 1)
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(minterCtx, "103")
+	require.NoError(t, err)
+
+	// Tokens minted later with the same IDs inherit none of it
+	mintTokens(t, mockStub, "101", "102", "103")
+
+	localizedURI, err := tokenContract.GetLocalizedURI(minterCtx, "101", "fr")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/nft/101", localizedURI)
+
+	recipients, err := tokenContract.GetTokenTransferAllowlist(minterCtx, "101")
+	require.NoError(t, err)
+	require.Empty(t, recipients)
+
+	price, err := tokenContract.GetLastSalePrice(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SalePrice{TokenID: "101"}, price)
+
+	offers, err := tokenContract.GetPendingOffersFrom(minterCtx, recipient)
+	require.NoError(t, err)
+	require.Empty(t, offers)
+
+	_, err = tokenContract.GetPrivateMetadata(minterCtx, "Org1MSPPrivateCollection", "101")
+	require.EqualError(t, err, "no private metadata for token 101 in collection Org1MSPPrivateCollection")
+
+	restriction, err := tokenContract.GetTokenRestriction(minterCtx, "102")
+	require.NoError(t, err)
+	require.Equal(t, "", restriction)
+
+	limit, err := tokenContract.GetTransferLimit(minterCtx, "103")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TransferLimit{TokenID: "103"}, limit)
+
+	// The URI is no longer frozen, and the token is not cooling down from the transfer of its predecessor
+	err = tokenContract.RevealMetadata(minterCtx, []string{"101"}, []string{"https://example.com/revealed/101"})
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "101")
+	require.NoError(t, err)
+}
+
+func TestSetBurnPolicy(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	operatorCtx := prepMocks(stub, org2MSP, operator)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	err := tokenContract.SetBurnPolicy(recipientCtx, true)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	_, err = tokenContract.SetApprovalForAll(minterCtx, operator, true)
+	require.NoError(t, err)
+	_, err = tokenContract.Approve(minterCtx, recipient, "102")
+	require.NoError(t, err)
+
+	// By default only the owner can burn
+	_, err = tokenContract.Burn(operatorCtx, "101")
+	require.EqualError(t, err, "non-fungible token 101 is not owned by operator")
+	_, err = tokenContract.Burn(recipientCtx, "102")
+	require.EqualError(t, err, "non-fungible token 102 is not owned by recipient")
+
+	err = tokenContract.SetBurnPolicy(minterCtx, true)
+	require.NoError(t, err)
+
+	_, err = tokenContract.Burn(operatorCtx, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(recipientCtx, "102")
+	require.NoError(t, err)
+
+	// The tokens are removed from the balance of the owner
+	balance, err := tokenContract.BalanceOf(minterCtx, minter)
+	require.NoError(t, err)
+	require.Equal(t, 0, balance)
+}
+
+func TestGetTokenCreationTx(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	stub.MockTransactionStart("mintTx")
+	_, err := tokenContract.MintWithTokenURI(minterCtx, "101", "https://example.com/nft/101")
+	require.NoError(t, err)
+	stub.MockTransactionEnd("mintTx")
+
+	// Later transfers keep the minting transaction
+	stub.MockTransactionStart("transferTx")
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	txID, err := tokenContract.GetTokenCreationTx(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, "mintTx", txID)
+
+	// A token stored before the transaction ID was recorded
+	nftKey, err := stub.CreateCompositeKey("nft", []string{"102"})
+	require.NoError(t, err)
+	stub.State[nftKey] = []byte(`{"tokenId":"102","owner":"minter","tokenURI":"","approved":""}`)
+	_, err = tokenContract.GetTokenCreationTx(minterCtx, "102")
+	require.EqualError(t, err, "no minting transaction is recorded for token 102")
+
+	_, err = tokenContract.GetTokenCreationTx(minterCtx, "999")
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
+
+func TestGetTokensCreatedBy(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	// The creator is kept through transfers, while burned tokens leave the index
+	_, err := tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(prepMocks(stub, org2MSP, recipient), recipient, operator, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(minterCtx, "103")
+	require.NoError(t, err)
+
+	nfts, err := tokenContract.GetTokensCreatedBy(minterCtx, minter)
+	require.NoError(t, err)
+	require.Len(t, nfts, 2)
+	require.Equal(t, "101", nfts[0].TokenID)
+	require.Equal(t, operator, nfts[0].Owner)
+	require.Equal(t, minter, nfts[0].Creator)
+	require.Equal(t, "102", nfts[1].TokenID)
+
+	nfts, err = tokenContract.GetTokensCreatedBy(minterCtx, recipient)
+	require.NoError(t, err)
+	require.Empty(t, nfts)
+}
+
+func TestTotalMintedAndBurned(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.Burn(minterCtx, "102")
+	require.NoError(t, err)
+
+	minted, err := tokenContract.GetTotalMinted(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, 3, minted)
+
+	burned, err := tokenContract.GetTotalBurned(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, 1, burned)
+
+	totalSupply, err := tokenContract.TotalSupply(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, 2, totalSupply)
+	require.Equal(t, minted-burned, totalSupply)
+}
+
+func TestSetOption(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+
+	_, err := tokenContract.SetOption(prepMocks(stub, org2MSP, recipient), "name", "symbol")
+	require.EqualError(t, err, "client is not authorized to set the name and symbol of the token")
+
+	_, err = tokenContract.SetOption(prepMocks(stub, org1MSP, minter), "Collection", "COL")
+	require.NoError(t, err)
+
+	name, err := tokenContract.Name(prepMocks(stub, org1MSP, minter))
+	require.NoError(t, err)
+	require.Equal(t, "Collection", name)
+
+	symbol, err := tokenContract.Symbol(prepMocks(stub, org1MSP, minter))
+	require.NoError(t, err)
+	require.Equal(t, "COL", symbol)
+}
+
+func TestHashedAccounts(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	minterHash, err := tokenContract.ClientAccountIDHash(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, "be9677d2ea649220f63b2ccf6275a49a0a64e9f59dd9961d69a01a8d525788f8", minterHash)
+	recipientHash, err := tokenContract.ClientAccountIDHash(recipientCtx)
+	require.NoError(t, err)
+
+	err = tokenContract.SetHashedAccounts(recipientCtx, true)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+	err = tokenContract.SetHashedAccounts(minterCtx, true)
+	require.NoError(t, err)
+
+	// The hash is now the account ID of the client and the owner of the tokens it mints
+	accountID, err := tokenContract.ClientAccountID(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, minterHash, accountID)
+
+	mintTokens(t, stub, "101")
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, minterHash, owner)
+
+	// Transfers and balances use the hashed IDs end-to-end
+	_, err = tokenContract.TransferFrom(minterCtx, minterHash, recipientHash, "101")
+	require.NoError(t, err)
+	balance, err := tokenContract.ClientAccountBalance(recipientCtx)
+	require.NoError(t, err)
+	require.Equal(t, 1, balance)
+	balance, err = tokenContract.BalanceOf(minterCtx, minterHash)
+	require.NoError(t, err)
+	require.Equal(t, 0, balance)
+
+	_, err = tokenContract.Burn(recipientCtx, "101")
+	require.NoError(t, err)
+
+	err = tokenContract.SetHashedAccounts(minterCtx, false)
+	require.EqualError(t, err, "hashed accounts must be configured before the first mint")
+}
+
+func TestGetClientMSP(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+
+	clientMSP, err := tokenContract.GetClientMSP(prepMocks(stub, org2MSP, recipient))
+	require.NoError(t, err)
+	require.Equal(t, org2MSP, clientMSP)
+
+	ctx := prepMocks(stub, org1MSP, minter)
+	ctx.GetClientIdentity().(*mocks.ClientIdentity).GetMSPIDReturns("", fmt.Errorf("no creator"))
+	_, err = tokenContract.GetClientMSP(ctx)
+	require.EqualError(t, err, "failed to get MSPID: no creator")
+}
+
+func TestGetClientIdentityAttribute(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	ctx := prepMocks(stub, org1MSP, minter)
+	clientIdentity := ctx.GetClientIdentity().(*mocks.ClientIdentity)
+
+	clientIdentity.GetAttributeValueReturns("true", true, nil)
+	attribute, err := tokenContract.GetClientIdentityAttribute(ctx, "minter")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.IdentityAttribute{Name: "minter", Value: "true", Found: true}, attribute)
+	require.Equal(t, "minter", clientIdentity.GetAttributeValueArgsForCall(0))
+
+	clientIdentity.GetAttributeValueReturns("", false, nil)
+	attribute, err = tokenContract.GetClientIdentityAttribute(ctx, "role")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.IdentityAttribute{Name: "role", Value: "", Found: false}, attribute)
+
+	clientIdentity.GetAttributeValueReturns("", false, fmt.Errorf("failed to parse certificate"))
+	_, err = tokenContract.GetClientIdentityAttribute(ctx, "role")
+	require.EqualError(t, err, "failed to get attribute role: failed to parse certificate")
+}
+
+func TestCompositeKeyErrors(t *testing.T) {
+	tokenContract := chaincode.SmartContract{}
+	nftJSON, err := json.Marshal(chaincode.Nft{TokenID: "101", Owner: minter})
+	require.NoError(t, err)
+
+	// failingStub fails to create composite keys for the given prefix, as it would for invalid attributes
+	failingStub := func(prefix string, state []byte) *mocks.ChaincodeStub {
+		stub := &mocks.ChaincodeStub{}
+		stub.CreateCompositeKeyStub = func(objectType string, attributes []string) (string, error) {
+			if objectType == prefix {
+				return "", fmt.Errorf("invalid attribute")
+			}
+			return objectType + fmt.Sprint(attributes), nil
+		}
+		// The token record is the only state, options such as hashed accounts are unset
+		stub.GetStateStub = func(key string) ([]byte, error) {
+			if strings.HasPrefix(key, "nft") {
+				return state, nil
+			}
+			return nil, nil
+		}
+		return stub
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		state  []byte
+		call   func(ctx contractapi.TransactionContextInterface) error
+	}{
+		{"OwnerOf", "nft", nftJSON, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := tokenContract.OwnerOf(ctx, "101")
+			return err
+		}},
+		{"MintWithTokenURI nft key", "nft", nil, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := tokenContract.MintWithTokenURI(ctx, "101", "")
+			return err
+		}},
+		{"MintWithTokenURI balance key", "balance", nil, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := tokenContract.MintWithTokenURI(ctx, "101", "")
+			return err
+		}},
+		{"TransferFrom nft key", "nft", nftJSON, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := tokenContract.TransferFrom(ctx, minter, recipient, "101")
+			return err
+		}},
+		{"TransferFrom approval key", "approval", nftJSON, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := tokenContract.TransferFrom(ctx, minter, recipient, "101")
+			return err
+		}},
+		{"Burn", "balance", nftJSON, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := tokenContract.Burn(ctx, "101")
+			return err
+		}},
+		{"Approve", "approval", nftJSON, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := tokenContract.Approve(ctx, operator, "101")
+			return err
+		}},
+		{"SetApprovalForAll", "approval", nil, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := tokenContract.SetApprovalForAll(ctx, operator, true)
+			return err
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := prepMocks(failingStub(test.prefix, test.state), org1MSP, minter)
+			err := test.call(ctx)
+			require.EqualError(t, err, "failed to create the composite key for prefix "+test.prefix+": invalid attribute")
+		})
+	}
+
+	// BalanceOf creates its partial composite key inside the range query
+	stub := &mocks.ChaincodeStub{}
+	stub.GetStateByPartialCompositeKeyReturns(nil, fmt.Errorf("invalid attribute"))
+	_, err = tokenContract.BalanceOf(prepMocks(stub, org1MSP, minter), minter)
+	require.EqualError(t, err, "failed to get balance keys for owner minter: invalid attribute")
+}
+
+func TestCanMint(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	check, err := tokenContract.CanMint(prepMocks(stub, org2MSP, recipient), "102")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.MintCheck{TokenID: "102", Reason: "client is not authorized to mint new tokens"}, check)
+
+	check, err = tokenContract.CanMint(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.MintCheck{TokenID: "101", Reason: "the token 101 is already minted"}, check)
+
+	check, err = tokenContract.CanMint(minterCtx, "abc")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.MintCheck{TokenID: "abc", Reason: "the tokenId abc is invalid. tokenId must be an integer"}, check)
+
+	// Checking a mint does not count against the rate limit
+	setTxTime(stub, 1000)
+	err = tokenContract.SetMintRateLimit(minterCtx, 1, 60)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		check, err = tokenContract.CanMint(minterCtx, "102")
+		require.NoError(t, err)
+		require.Equal(t, &chaincode.MintCheck{TokenID: "102", Allowed: true}, check)
+	}
+
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "102", "https://example.com/nft/102")
+	require.NoError(t, err)
+	check, err = tokenContract.CanMint(minterCtx, "103")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.MintCheck{TokenID: "103", Reason: "mint rate limit of 1 per 60 seconds reached until 1060"}, check)
+}
+
+func TestEmptyOwnerGuard(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	// Corrupted state leaves a token without an owner
+	nftKey, err := stub.CreateCompositeKey("nft", []string{"101"})
+	require.NoError(t, err)
+	stub.State[nftKey] = []byte(`{"tokenId":"101","owner":"","tokenURI":"","approved":""}`)
+
+	_, err = tokenContract.OwnerOf(minterCtx, "101")
+	require.EqualError(t, err, "no owner is assigned to token 101")
+	_, err = tokenContract.TransferFrom(minterCtx, "", recipient, "101")
+	require.EqualError(t, err, "no owner is assigned to token 101")
+	_, err = tokenContract.Approve(minterCtx, operator, "101")
+	require.EqualError(t, err, "no owner is assigned to token 101")
+}
+
+func TestZeroAddress(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.TransferFrom(minterCtx, minter, "0x0", "101")
+	require.EqualError(t, err, "invalid recipient 0x0")
+	_, err = tokenContract.TransferSale(minterCtx, minter, "0x0", "101", 100)
+	require.EqualError(t, err, "invalid recipient 0x0")
+	err = tokenContract.OfferTransfer(minterCtx, "0x0", "101")
+	require.EqualError(t, err, "invalid recipient 0x0")
+	err = tokenContract.SetRecoveryAddress(minterCtx, "0x0")
+	require.EqualError(t, err, "invalid recovery address 0x0")
+
+	_, err = tokenContract.MintWithTokenURI(prepMocks(stub, org1MSP, "0x0"), "102", "")
+	require.EqualError(t, err, "invalid minter 0x0")
+
+	owner, err := tokenContract.OwnerOf(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, minter, owner)
+}
+
+func TestGetTokenFields(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	fields, err := tokenContract.GetTokenFields(minterCtx, "101", []string{"owner", "tokenURI"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"owner": minter, "tokenURI": "https://example.com/nft/101"}, fields)
+
+	fields, err = tokenContract.GetTokenFields(minterCtx, "101", []string{"supply", "approved"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"supply": "0", "approved": ""}, fields)
+
+	_, err = tokenContract.GetTokenFields(minterCtx, "101", []string{"owner", "color"})
+	require.EqualError(t, err, "unknown token field color")
+
+	_, err = tokenContract.GetTokenFields(minterCtx, "999", []string{"owner"})
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
+
+func TestTryGetToken(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	lookup, err := tokenContract.TryGetToken(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TokenLookup{TokenID: "101", Found: true, Token: &chaincode.Nft{TokenID: "101", Owner: minter, Creator: minter, MintTxID: "tx1", TokenURI: "https://example.com/nft/101"}}, lookup)
+
+	lookup, err = tokenContract.TryGetToken(minterCtx, "999")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TokenLookup{TokenID: "999"}, lookup)
+
+	// A failure to read the ledger is still an error
+	failingStub := &mocks.ChaincodeStub{}
+	failingStub.GetStateReturns(nil, fmt.Errorf("ledger unavailable"))
+	_, err = tokenContract.TryGetToken(prepMocks(failingStub, org1MSP, minter), "101")
+	require.EqualError(t, err, "failed to read token 101 from world state: ledger unavailable")
+}
+
+func TestMintWithReceipt(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	stub.MockTransactionStart("mintTx")
+	setTxTime(stub, 1000)
+	receipt, err := tokenContract.MintWithReceipt(minterCtx, "102", "https://example.com/nft/102")
+	require.NoError(t, err)
+	stub.MockTransactionEnd("mintTx")
+	require.Equal(t, &chaincode.MintReceipt{TokenID: "102", Owner: minter, TxID: "mintTx", Timestamp: 1000, Sequence: 2}, receipt)
+
+	// The receipt matches the minted state
+	sequence, err := tokenContract.GetTokenSequence(minterCtx, "102")
+	require.NoError(t, err)
+	require.Equal(t, receipt.Sequence, sequence)
+	txID, err := tokenContract.GetTokenCreationTx(minterCtx, "102")
+	require.NoError(t, err)
+	require.Equal(t, receipt.TxID, txID)
+	owner, err := tokenContract.OwnerOf(minterCtx, "102")
+	require.NoError(t, err)
+	require.Equal(t, receipt.Owner, owner)
+
+	stub.MockTransactionStart("untrackedTx")
+	err = tokenContract.SetModificationTracking(minterCtx, false)
+	require.NoError(t, err)
+	receipt, err = tokenContract.MintWithReceipt(minterCtx, "103", "")
+	require.NoError(t, err)
+	require.Equal(t, 0, receipt.Sequence)
+
+	_, err = tokenContract.MintWithReceipt(minterCtx, "103", "")
+	require.EqualError(t, err, "the token 103 is already minted")
+}
+
+func TestBurnWithReceipt(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	_, err := tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	stub.MockTransactionStart("burnTx")
+	setTxTime(stub, 3000)
+	receipt, err := tokenContract.BurnWithReceipt(recipientCtx, "101")
+	require.NoError(t, err)
+	stub.MockTransactionEnd("burnTx")
+	require.Equal(t, &chaincode.BurnReceipt{TokenID: "101", Owner: recipient, TxID: "burnTx", Timestamp: 3000}, receipt)
+
+	// A second burn says the token is gone because it was burned
+	stub.MockTransactionStart("burnAgainTx")
+	_, err = tokenContract.BurnWithReceipt(recipientCtx, "101")
+	require.EqualError(t, err, "token 101 was already burned")
+	_, err = tokenContract.Burn(recipientCtx, "101")
+	require.EqualError(t, err, "token 101 was already burned")
+
+	_, err = tokenContract.BurnWithReceipt(recipientCtx, "999")
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
@@ -0,0 +1,409 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+)
+
+// Define objectType names for prefix
+const frozenURIPrefix = "frozenURI"
+const localizedURIPrefix = "localizedURI"
+const tokenURIPrefix = "tokenURI"
+
+// Define key names for options
+const baseURIKey = "baseURI"
+
+// SetBaseURI sets the URI prefix used for tokens minted without their own token URI
+func (s *SmartContract) SetBaseURI(ctx contractapi.TransactionContextInterface, baseURI string) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// An empty value would represent a delete, which simply removes the base URI
+	err = ctx.GetStub().PutState(baseURIKey, []byte(baseURI))
+	if err != nil {
+		return fmt.Errorf("failed to set base URI: %v", err)
+	}
+
+	return nil
+}
+
+// GetBaseURI returns the URI prefix used for tokens without their own token URI, possibly empty
+func (s *SmartContract) GetBaseURI(ctx contractapi.TransactionContextInterface) (string, error) {
+	return readBaseURI(ctx)
+}
+
+// ResolveTokenURI returns the URI a client should fetch for the metadata of a token
+// The token's own URI takes precedence, otherwise the base URI followed by the token ID is used.
+// It is an error if neither is set.
+func (s *SmartContract) ResolveTokenURI(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	return resolveTokenURI(ctx, nft)
+}
+
+// MintWithContentType creates a new non-fungible token with a hint of the media type its metadata is served as
+// Renderers can read the hint with GetTokenContentType instead of fetching the metadata first.
+// This function triggers a Transfer event
+func (s *SmartContract) MintWithContentType(ctx contractapi.TransactionContextInterface, tokenID string, tokenURI string, contentType string) (*Nft, error) {
+	nft, err := mintHelper(ctx, tokenID, tokenURI, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	nft.ContentType = contentType
+	err = putNFT(ctx, nft)
+	if err != nil {
+		return nil, err
+	}
+
+	return nft, nil
+}
+
+// GetTokenContentType returns the media type hint of a token, empty if none was given at mint
+func (s *SmartContract) GetTokenContentType(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	return nft.ContentType, nil
+}
+
+// SetLocalizedURI sets the URI of a token's metadata for one locale, such as "fr" or "pt-BR"
+// Only the owner, the minter for an edition, can set it. An empty URI removes the locale.
+func (s *SmartContract) SetLocalizedURI(ctx contractapi.TransactionContextInterface, tokenID string, locale string, uri string) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if nft.Owner != sender {
+		return fmt.Errorf("non-fungible token %s is not owned by %s", tokenID, sender)
+	}
+	if locale == "" {
+		return fmt.Errorf("locale must not be empty")
+	}
+
+	localizedKey, err := ctx.GetStub().CreateCompositeKey(localizedURIPrefix, []string{tokenID, locale})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", localizedURIPrefix, err)
+	}
+
+	if uri == "" {
+		err = ctx.GetStub().DelState(localizedKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete %s URI of token %s: %v", locale, tokenID, err)
+		}
+		return nil
+	}
+
+	err = ctx.GetStub().PutState(localizedKey, []byte(uri))
+	if err != nil {
+		return fmt.Errorf("failed to put %s URI of token %s: %v", locale, tokenID, err)
+	}
+
+	return nil
+}
+
+// GetLocalizedURI returns the URI of a token's metadata for a locale, or its default token URI if the locale is not set
+func (s *SmartContract) GetLocalizedURI(ctx contractapi.TransactionContextInterface, tokenID string, locale string) (string, error) {
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	localizedKey, err := ctx.GetStub().CreateCompositeKey(localizedURIPrefix, []string{tokenID, locale})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", localizedURIPrefix, err)
+	}
+	uriBytes, err := ctx.GetStub().GetState(localizedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s URI of token %s: %v", locale, tokenID, err)
+	}
+	if len(uriBytes) == 0 {
+		return nft.TokenURI, nil
+	}
+
+	return string(uriBytes), nil
+}
+
+// FreezeTokenURI permanently prevents the URI of a token from being changed by RevealMetadata
+func (s *SmartContract) FreezeTokenURI(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	exists, err := nftExists(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the tokenId %s is invalid. It does not exist", tokenID)
+	}
+
+	return setFlag(ctx, frozenURIPrefix, tokenID, true)
+}
+
+// RevealMetadata replaces the URIs of minted tokens, typically placeholders, in one transaction
+// uris[i] becomes the URI of tokenIDs[i]. Nothing is changed if any of the tokens is frozen.
+// This function triggers a single MetadataUpdate event listing the updated tokens
+func (s *SmartContract) RevealMetadata(ctx contractapi.TransactionContextInterface, tokenIDs []string, uris []string) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(tokenIDs) != len(uris) {
+		return fmt.Errorf("got %d token IDs but %d URIs", len(tokenIDs), len(uris))
+	}
+	err = checkBatchSize(ctx, len(tokenIDs))
+	if err != nil {
+		return err
+	}
+
+	// Read every token before writing any, so that a frozen token leaves all URIs unchanged
+	nfts := make([]*Nft, 0, len(tokenIDs))
+	seen := make(map[string]bool, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		if seen[tokenID] {
+			return fmt.Errorf("token %s appears more than once", tokenID)
+		}
+		seen[tokenID] = true
+
+		frozen, err := hasFlag(ctx, frozenURIPrefix, tokenID)
+		if err != nil {
+			return err
+		}
+		if frozen {
+			return fmt.Errorf("the URI of token %s is frozen", tokenID)
+		}
+
+		nft, err := readNFT(ctx, tokenID)
+		if err != nil {
+			return err
+		}
+		nfts = append(nfts, nft)
+	}
+
+	for i, nft := range nfts {
+		err = setTokenURI(ctx, nft, uris[i])
+		if err != nil {
+			return err
+		}
+		err = putNFT(ctx, nft)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = touchTokens(ctx, tokenIDs)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "MetadataUpdate", events.MetadataUpdateEvent{TokenIDs: tokenIDs})
+}
+
+// GetTokensByURI returns the IDs of the non-fungible tokens whose own token URI is uri
+// Tokens that rely on the base URI are not matched.
+// There is a key record for every token in the format of tokenURIPrefix.tokenURI.tokenId, so only the
+// tokens with this URI are visited, on both LevelDB and CouchDB.
+func (s *SmartContract) GetTokensByURI(ctx contractapi.TransactionContextInterface, uri string) ([]string, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(tokenURIPrefix, []string{uri})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token URI keys for %s: %v", uri, err)
+	}
+	defer iterator.Close()
+
+	tokenIDs := []string{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token URI key: %v", err)
+		}
+
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split the composite key %s: %v", queryResponse.Key, err)
+		}
+		tokenIDs = append(tokenIDs, compositeKeyParts[1])
+	}
+
+	return tokenIDs, nil
+}
+
+// Reissue migrates a token to a new URI in place, keeping its ID, creator and ownership log
+// Burning and minting anew would lose the token's identity, so the migration is noted in the ownership
+// log instead. It must be submitted by the owner or an admin, and a frozen URI cannot be reissued.
+// This function triggers a Reissued event
+func (s *SmartContract) Reissue(ctx contractapi.TransactionContextInterface, tokenID string, newURI string) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if nft.Owner != sender {
+		err = authorizeAdmin(ctx)
+		if err != nil {
+			return fmt.Errorf("non-fungible token %s is not owned by %s", tokenID, sender)
+		}
+	}
+
+	frozen, err := hasFlag(ctx, frozenURIPrefix, tokenID)
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return fmt.Errorf("the URI of token %s is frozen", tokenID)
+	}
+
+	previousURI := nft.TokenURI
+	err = setTokenURI(ctx, nft, newURI)
+	if err != nil {
+		return err
+	}
+	err = putNFT(ctx, nft)
+	if err != nil {
+		return err
+	}
+
+	err = appendOwnerChange(ctx, tokenID, OwnerChange{From: nft.Owner, To: nft.Owner, Reissued: true, PreviousURI: previousURI})
+	if err != nil {
+		return err
+	}
+
+	err = touchToken(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "Reissued", events.ReissuedEvent{TokenID: tokenID, PreviousURI: previousURI, TokenURI: newURI})
+}
+
+// GetTokensWithoutURI returns the IDs of the non-fungible tokens that have no metadata URI to resolve
+// These are the tokens without their own token URI, and none at all while a base URI is set, since
+// every token then resolves against it. Like GetTokensByURI, only the matching tokens are visited.
+func (s *SmartContract) GetTokensWithoutURI(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	baseURI, err := readBaseURI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if baseURI != "" {
+		return []string{}, nil
+	}
+
+	return s.GetTokensByURI(ctx, "")
+}
+
+// Helper Functions
+
+func resolveTokenURI(ctx contractapi.TransactionContextInterface, nft *Nft) (string, error) {
+	if nft.TokenURI != "" {
+		return nft.TokenURI, nil
+	}
+
+	baseURI, err := readBaseURI(ctx)
+	if err != nil {
+		return "", err
+	}
+	if baseURI != "" {
+		return baseURI + nft.TokenID, nil
+	}
+
+	return "", fmt.Errorf("no URI is set for token %s", nft.TokenID)
+}
+
+func readBaseURI(ctx contractapi.TransactionContextInterface) (string, error) {
+	baseURIBytes, err := ctx.GetStub().GetState(baseURIKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get base URI: %v", err)
+	}
+
+	return string(baseURIBytes), nil
+}
+
+// setTokenURI changes the own URI of a token and moves it in the token URI index, the caller puts the token
+// Dependant functions include RevealMetadata, Reissue and UpdateTokenURISigned
+func setTokenURI(ctx contractapi.TransactionContextInterface, nft *Nft, uri string) error {
+	err := deleteTokenURIKey(ctx, nft.TokenURI, nft.TokenID)
+	if err != nil {
+		return err
+	}
+	err = putTokenURIKey(ctx, uri, nft.TokenID)
+	if err != nil {
+		return err
+	}
+
+	nft.TokenURI = uri
+
+	return nil
+}
+
+func putTokenURIKey(ctx contractapi.TransactionContextInterface, uri string, tokenID string) error {
+	uriKey, err := ctx.GetStub().CreateCompositeKey(tokenURIPrefix, []string{uri, tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", tokenURIPrefix, err)
+	}
+	err = ctx.GetStub().PutState(uriKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put token URI key %s: %v", uriKey, err)
+	}
+
+	return nil
+}
+
+func deleteTokenURIKey(ctx contractapi.TransactionContextInterface, uri string, tokenID string) error {
+	uriKey, err := ctx.GetStub().CreateCompositeKey(tokenURIPrefix, []string{uri, tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", tokenURIPrefix, err)
+	}
+	err = ctx.GetStub().DelState(uriKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete token URI key %s: %v", uriKey, err)
+	}
+
+	return nil
+}
+
+// deleteLocalizedURIs removes the localized URIs of a token for every locale
+func deleteLocalizedURIs(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(localizedURIPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to get localized URIs of token %s: %v", tokenID, err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("failed to read localized URI of token %s: %v", tokenID, err)
+		}
+
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return fmt.Errorf("failed to delete localized URI of token %s: %v", tokenID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTokenURI(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.MintWithTokenURI(adminCtx, "101", "ipfs://token-101")
+	require.NoError(t, err)
+	_, err = tokenContract.MintWithTokenURI(adminCtx, "102", "")
+	require.NoError(t, err)
+
+	// Neither a token URI nor a base URI
+	_, err = tokenContract.ResolveTokenURI(adminCtx, "102")
+	require.EqualError(t, err, "no URI is set for token 102")
+
+	err = tokenContract.SetBaseURI(prepMocks(stub, org2MSP, recipient), "https://example.com/nft/")
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+	require.NoError(t, tokenContract.SetBaseURI(adminCtx, "https://example.com/nft/"))
+
+	// The base URI followed by the token ID
+	uri, err := tokenContract.ResolveTokenURI(adminCtx, "102")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/nft/102", uri)
+
+	// The token URI takes precedence over the base URI
+	uri, err = tokenContract.ResolveTokenURI(adminCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, "ipfs://token-101", uri)
+
+	_, err = tokenContract.ResolveTokenURI(adminCtx, "103")
+	require.EqualError(t, err, "the tokenId 103 is invalid. It does not exist")
+}
+
+func TestRevealMetadata(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+	drainEvents(stub)
+
+	err := tokenContract.RevealMetadata(prepMocks(stub, org2MSP, recipient), []string{"101"}, []string{"ipfs://101"})
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	err = tokenContract.RevealMetadata(adminCtx, []string{"101", "102"}, []string{"ipfs://101"})
+	require.EqualError(t, err, "got 2 token IDs but 1 URIs")
+
+	err = tokenContract.RevealMetadata(adminCtx, []string{"101", "101"}, []string{"ipfs://101", "ipfs://101"})
+	require.EqualError(t, err, "token 101 appears more than once")
+
+	err = tokenContract.RevealMetadata(adminCtx, []string{"101", "102"}, []string{"ipfs://101", "ipfs://102"})
+	require.NoError(t, err)
+
+	for _, tokenID := range []string{"101", "102"} {
+		uri, err := tokenContract.TokenURI(adminCtx, tokenID)
+		require.NoError(t, err)
+		require.Equal(t, "ipfs://"+tokenID, uri)
+	}
+
+	events := drainEvents(stub)
+	require.Len(t, events, 1)
+	require.Equal(t, "MetadataUpdate", events[0].EventName)
+	require.JSONEq(t, `{"tokenIds":["101","102"]}`, string(events[0].Payload))
+
+	// A frozen token blocks the whole reveal
+	err = tokenContract.FreezeTokenURI(adminCtx, "102")
+	require.NoError(t, err)
+	err = tokenContract.RevealMetadata(adminCtx, []string{"103", "102"}, []string{"ipfs://103", "ipfs://other"})
+	require.EqualError(t, err, "the URI of token 102 is frozen")
+
+	uri, err := tokenContract.TokenURI(adminCtx, "103")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/nft/103", uri)
+
+	err = tokenContract.FreezeTokenURI(adminCtx, "999")
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
+
+func TestLocalizedURI(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetLocalizedURI(prepMocks(stub, org2MSP, recipient), "101", "fr", "https://example.com/fr/101")
+	require.EqualError(t, err, "non-fungible token 101 is not owned by recipient")
+
+	err = tokenContract.SetLocalizedURI(minterCtx, "101", "fr", "https://example.com/fr/101")
+	require.NoError(t, err)
+
+	uri, err := tokenContract.GetLocalizedURI(minterCtx, "101", "fr")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/fr/101", uri)
+
+	// A locale that is not set falls back to the token URI
+	uri, err = tokenContract.GetLocalizedURI(minterCtx, "101", "de")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/nft/101", uri)
+
+	err = tokenContract.SetLocalizedURI(minterCtx, "101", "fr", "")
+	require.NoError(t, err)
+	uri, err = tokenContract.GetLocalizedURI(minterCtx, "101", "fr")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/nft/101", uri)
+}
+
+func TestGetTokensByURI(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	_, err := tokenContract.MintWithTokenURI(minterCtx, "101", "https://example.com/shared")
+	require.NoError(t, err)
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "102", "https://example.com/shared")
+	require.NoError(t, err)
+	_, err = tokenContract.MintWithTokenURI(minterCtx, "103", "https://example.com/nft/103")
+	require.NoError(t, err)
+
+	tokenIDs, err := tokenContract.GetTokensByURI(minterCtx, "https://example.com/shared")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"101", "102"}, tokenIDs)
+
+	tokenIDs, err = tokenContract.GetTokensByURI(minterCtx, "https://example.com/nft/103")
+	require.NoError(t, err)
+	require.Equal(t, []string{"103"}, tokenIDs)
+
+	tokenIDs, err = tokenContract.GetTokensByURI(minterCtx, "https://example.com/unknown")
+	require.NoError(t, err)
+	require.Empty(t, tokenIDs)
+
+	// A token is listed under its current URI only, and not once it is burned
+	err = tokenContract.Reissue(minterCtx, "102", "https://example.com/nft/103")
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(minterCtx, "101")
+	require.NoError(t, err)
+	tokenIDs, err = tokenContract.GetTokensByURI(minterCtx, "https://example.com/shared")
+	require.NoError(t, err)
+	require.Empty(t, tokenIDs)
+	tokenIDs, err = tokenContract.GetTokensByURI(minterCtx, "https://example.com/nft/103")
+	require.NoError(t, err)
+	require.Equal(t, []string{"102", "103"}, tokenIDs)
+}
+
+func TestTokenContentType(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	nft, err := tokenContract.MintWithContentType(minterCtx, "101", "https://example.com/nft/101", "model/gltf-binary")
+	require.NoError(t, err)
+	require.Equal(t, "model/gltf-binary", nft.ContentType)
+	mintTokens(t, stub, "102")
+
+	contentType, err := tokenContract.GetTokenContentType(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, "model/gltf-binary", contentType)
+
+	// The hint survives later updates of the token record
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	contentType, err = tokenContract.GetTokenContentType(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, "model/gltf-binary", contentType)
+
+	contentType, err = tokenContract.GetTokenContentType(minterCtx, "102")
+	require.NoError(t, err)
+	require.Empty(t, contentType)
+
+	_, err = tokenContract.GetTokenContentType(minterCtx, "999")
+	require.EqualError(t, err, "the tokenId 999 is invalid. It does not exist")
+}
+
+func TestGetTokensWithoutURI(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	for _, tokenID := range []string{"102", "103"} {
+		_, err := tokenContract.MintWithTokenURI(minterCtx, tokenID, "")
+		require.NoError(t, err)
+	}
+
+	tokenIDs, err := tokenContract.GetTokensWithoutURI(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"102", "103"}, tokenIDs)
+
+	// Revealed tokens are no longer listed
+	err = tokenContract.RevealMetadata(minterCtx, []string{"102"}, []string{"https://example.com/nft/102"})
+	require.NoError(t, err)
+	tokenIDs, err = tokenContract.GetTokensWithoutURI(minterCtx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"103"}, tokenIDs)
+
+	// Every token resolves once a base URI is set
+	err = tokenContract.SetBaseURI(minterCtx, "https://example.com/base/")
+	require.NoError(t, err)
+	tokenIDs, err = tokenContract.GetTokensWithoutURI(minterCtx)
+	require.NoError(t, err)
+	require.Empty(t, tokenIDs)
+}
+
+func TestReissue(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	setTxTime(stub, 1000)
+	mintTokens(t, stub, "101", "102")
+	_, err := tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	err = tokenContract.Reissue(prepMocks(stub, org2MSP, operator), "101", "https://example.com/v2/101")
+	require.EqualError(t, err, "non-fungible token 101 is not owned by "+operator)
+
+	// The owner migrates the token, keeping its identity
+	drainEvents(stub)
+	err = tokenContract.Reissue(recipientCtx, "101", "https://example.com/v2/101")
+	require.NoError(t, err)
+
+	nft, err := tokenContract.TryGetToken(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Nft{TokenID: "101", Owner: recipient, Creator: minter, MintTxID: "tx1", TokenURI: "https://example.com/v2/101"}, nft.Token)
+
+	emitted := drainEvents(stub)
+	require.Len(t, emitted, 1)
+	require.Equal(t, "Reissued", emitted[0].EventName)
+	require.JSONEq(t, `{"tokenId":"101","previousURI":"https://example.com/nft/101","tokenURI":"https://example.com/v2/101"}`, string(emitted[0].Payload))
+
+	// The provenance notes the reissue, which is not a transfer
+	changes, err := tokenContract.GetOwnerChangeEvents(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, chaincode.OwnerChange{From: recipient, To: recipient, TxID: "tx1", Timestamp: 1000, Reissued: true, PreviousURI: "https://example.com/nft/101"}, changes[2])
+	page, err := tokenContract.ExportTransferEdges(minterCtx, 1, "")
+	require.NoError(t, err)
+	require.Len(t, page.Edges, 2)
+
+	// An admin may reissue any token, unless its URI is frozen
+	err = tokenContract.Reissue(minterCtx, "101", "https://example.com/v3/101")
+	require.NoError(t, err)
+	err = tokenContract.FreezeTokenURI(minterCtx, "102")
+	require.NoError(t, err)
+	err = tokenContract.Reissue(minterCtx, "102", "https://example.com/v2/102")
+	require.EqualError(t, err, "the URI of token 102 is frozen")
+}
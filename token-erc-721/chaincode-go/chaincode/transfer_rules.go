@@ -0,0 +1,531 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const transferRulePrefix = "transferRule"
+const allowlistPrefix = "allowlist"
+const blocklistPrefix = "blocklist"
+const lastTransferPrefix = "lastTransfer"
+const tokenAllowlistPrefix = "tokenAllowlist"
+
+// Define key names for options
+const transferCooldownKey = "transferCooldown"
+const balanceCapKey = "balanceCap"
+const transfersEnabledAtKey = "transfersEnabledAt"
+
+// TransferRule reports whether a named transfer-validation rule is enabled
+type TransferRule struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// transferRule is a named predicate that TransferFrom evaluates before a token changes hands
+// check returns the reason the transfer is rejected, or nil if the rule is satisfied
+type transferRule struct {
+	name  string
+	check func(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) error
+}
+
+// transferRules is the registry of available rules, in the order they are enforced
+var transferRules = []transferRule{
+	{"allowlist", checkAllowlistRule},
+	{"blocklist", checkBlocklistRule},
+	{"cooldown", checkCooldownRule},
+	{"cap", checkCapRule},
+	{"profile", checkProfileRule},
+}
+
+// EnableTransferRule turns a named transfer-validation rule on or off
+func (s *SmartContract) EnableTransferRule(ctx contractapi.TransactionContextInterface, ruleName string, enabled bool) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if findTransferRule(ruleName) == nil {
+		return fmt.Errorf("unknown transfer rule %s", ruleName)
+	}
+
+	ruleKey, err := ctx.GetStub().CreateCompositeKey(transferRulePrefix, []string{ruleName})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", transferRulePrefix, err)
+	}
+	ruleJSON, err := json.Marshal(TransferRule{Name: ruleName, Enabled: enabled})
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(ruleKey, ruleJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put transfer rule %s: %v", ruleName, err)
+	}
+
+	return nil
+}
+
+// ListTransferRules returns every registered transfer rule, in enforcement order, with its enabled flag
+func (s *SmartContract) ListTransferRules(ctx contractapi.TransactionContextInterface) ([]TransferRule, error) {
+	rules := make([]TransferRule, 0, len(transferRules))
+	for _, rule := range transferRules {
+		enabled, err := isTransferRuleEnabled(ctx, rule.name)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, TransferRule{Name: rule.name, Enabled: enabled})
+	}
+
+	return rules, nil
+}
+
+// SetAllowlisted adds or removes an account from the recipient allowlist used by the allowlist rule
+func (s *SmartContract) SetAllowlisted(ctx contractapi.TransactionContextInterface, account string, allowed bool) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	return setFlag(ctx, allowlistPrefix, account, allowed)
+}
+
+// SetBlocklisted adds or removes an account from the blocklist used by the blocklist rule
+func (s *SmartContract) SetBlocklisted(ctx contractapi.TransactionContextInterface, account string, blocked bool) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	return setFlag(ctx, blocklistPrefix, account, blocked)
+}
+
+// SetTransferCooldown sets the number of seconds a token must rest between transfers under the cooldown rule
+func (s *SmartContract) SetTransferCooldown(ctx contractapi.TransactionContextInterface, seconds int) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if seconds < 0 {
+		return fmt.Errorf("transfer cooldown cannot be negative")
+	}
+
+	err = ctx.GetStub().PutState(transferCooldownKey, []byte(strconv.Itoa(seconds)))
+	if err != nil {
+		return fmt.Errorf("failed to set transfer cooldown: %v", err)
+	}
+
+	return nil
+}
+
+// SetBalanceCap sets the maximum number of tokens a recipient may hold under the cap rule
+func (s *SmartContract) SetBalanceCap(ctx contractapi.TransactionContextInterface, max int) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if max <= 0 {
+		return fmt.Errorf("balance cap must be a positive integer")
+	}
+
+	err = ctx.GetStub().PutState(balanceCapKey, []byte(strconv.Itoa(max)))
+	if err != nil {
+		return fmt.Errorf("failed to set balance cap: %v", err)
+	}
+
+	return nil
+}
+
+// SetTransfersEnabledAt rejects every transfer before the given Unix time in seconds, while minting stays open
+// A time of zero removes the gate
+func (s *SmartContract) SetTransfersEnabledAt(ctx contractapi.TransactionContextInterface, unixTime int64) error {
+	err := authorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if unixTime < 0 {
+		return fmt.Errorf("transfers enabled time cannot be negative")
+	}
+
+	if unixTime == 0 {
+		err = ctx.GetStub().DelState(transfersEnabledAtKey)
+		if err != nil {
+			return fmt.Errorf("failed to remove transfers enabled time: %v", err)
+		}
+		return nil
+	}
+
+	err = ctx.GetStub().PutState(transfersEnabledAtKey, []byte(strconv.FormatInt(unixTime, 10)))
+	if err != nil {
+		return fmt.Errorf("failed to set transfers enabled time: %v", err)
+	}
+
+	return nil
+}
+
+// SetTokenTransferAllowlist restricts the recipients a non-fungible token may be transferred to
+// Only the owner, the minter for an edition, can set the list. An empty list lifts the restriction.
+// Unlike the allowlist rule, the list applies to one token and is enforced without being enabled.
+func (s *SmartContract) SetTokenTransferAllowlist(ctx contractapi.TransactionContextInterface, tokenID string, recipients []string) error {
+
+	// Get ID of submitting client identity
+	sender, err := clientAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	nft, err := readNFT(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if nft.Owner != sender {
+		return fmt.Errorf("non-fungible token %s is not owned by %s", tokenID, sender)
+	}
+
+	allowlistKey, err := ctx.GetStub().CreateCompositeKey(tokenAllowlistPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", tokenAllowlistPrefix, err)
+	}
+
+	if len(recipients) == 0 {
+		err = ctx.GetStub().DelState(allowlistKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete transfer allowlist of token %s: %v", tokenID, err)
+		}
+		return nil
+	}
+
+	recipientsJSON, err := json.Marshal(recipients)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(allowlistKey, recipientsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put transfer allowlist of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
+
+// GetTokenTransferAllowlist returns the recipients a non-fungible token may be transferred to, empty if unrestricted
+func (s *SmartContract) GetTokenTransferAllowlist(ctx contractapi.TransactionContextInterface, tokenID string) ([]string, error) {
+	return readTokenAllowlist(ctx, tokenID)
+}
+
+// Helper Functions
+
+// applyTransferRules checks the launch gate and the transfer allowlist of the token, then runs every enabled
+// transfer rule in registry order and stops at the first failure
+func applyTransferRules(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) error {
+	enabledAtBytes, err := ctx.GetStub().GetState(transfersEnabledAtKey)
+	if err != nil {
+		return fmt.Errorf("failed to read transfers enabled time: %v", err)
+	}
+	if len(enabledAtBytes) > 0 {
+		enabledAt, _ := strconv.ParseInt(string(enabledAtBytes), 10, 64) // Error handling not needed since FormatInt() was used when setting the time, guaranteeing it was an integer.
+		now, err := txTimestamp(ctx)
+		if err != nil {
+			return err
+		}
+		if now < enabledAt {
+			return fmt.Errorf("transfers are disabled until %d", enabledAt)
+		}
+	}
+
+	recipients, err := readTokenAllowlist(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if len(recipients) > 0 && !containsString(recipients, to) {
+		return fmt.Errorf("recipient %s is not on the transfer allowlist of token %s", to, tokenID)
+	}
+
+	for _, rule := range transferRules {
+		enabled, err := isTransferRuleEnabled(ctx, rule.name)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			continue
+		}
+
+		err = rule.check(ctx, from, to, tokenID)
+		if err != nil {
+			return fmt.Errorf("transfer rejected by rule %s: %v", rule.name, err)
+		}
+	}
+
+	return nil
+}
+
+// recordTransferTime stores when a token last changed hands so the cooldown rule can be enforced
+func recordTransferTime(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	lastTransferKey, err := ctx.GetStub().CreateCompositeKey(lastTransferPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", lastTransferPrefix, err)
+	}
+	err = ctx.GetStub().PutState(lastTransferKey, []byte(strconv.FormatInt(now, 10)))
+	if err != nil {
+		return fmt.Errorf("failed to put last transfer time of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
+
+func deleteTransferTime(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	lastTransferKey, err := ctx.GetStub().CreateCompositeKey(lastTransferPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", lastTransferPrefix, err)
+	}
+	err = ctx.GetStub().DelState(lastTransferKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete last transfer time of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
+
+func findTransferRule(ruleName string) *transferRule {
+	for i := range transferRules {
+		if transferRules[i].name == ruleName {
+			return &transferRules[i]
+		}
+	}
+
+	return nil
+}
+
+func isTransferRuleEnabled(ctx contractapi.TransactionContextInterface, ruleName string) (bool, error) {
+	ruleKey, err := ctx.GetStub().CreateCompositeKey(transferRulePrefix, []string{ruleName})
+	if err != nil {
+		return false, fmt.Errorf("failed to create the composite key for prefix %s: %v", transferRulePrefix, err)
+	}
+	ruleBytes, err := ctx.GetStub().GetState(ruleKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read transfer rule %s: %v", ruleName, err)
+	}
+
+	// Rules are disabled until an admin enables them
+	if len(ruleBytes) == 0 {
+		return false, nil
+	}
+
+	var rule TransferRule
+	err = json.Unmarshal(ruleBytes, &rule)
+	if err != nil {
+		return false, fmt.Errorf("failed to unmarshal transfer rule %s: %v", ruleName, err)
+	}
+
+	return rule.Enabled, nil
+}
+
+// setFlag records membership of a key, such as an account or a token ID, in a set keyed by prefix.key
+// A cleared flag deletes the key so that the set can be enumerated by partial composite key
+func setFlag(ctx contractapi.TransactionContextInterface, prefix string, key string, flag bool) error {
+	flagKey, err := ctx.GetStub().CreateCompositeKey(prefix, []string{key})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", prefix, err)
+	}
+
+	if !flag {
+		err = ctx.GetStub().DelState(flagKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete %s entry for %s: %v", prefix, key, err)
+		}
+		return nil
+	}
+
+	err = ctx.GetStub().PutState(flagKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put %s entry for %s: %v", prefix, key, err)
+	}
+
+	return nil
+}
+
+func hasFlag(ctx contractapi.TransactionContextInterface, prefix string, key string) (bool, error) {
+	flagKey, err := ctx.GetStub().CreateCompositeKey(prefix, []string{key})
+	if err != nil {
+		return false, fmt.Errorf("failed to create the composite key for prefix %s: %v", prefix, err)
+	}
+	flagBytes, err := ctx.GetStub().GetState(flagKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s entry for %s: %v", prefix, key, err)
+	}
+
+	return len(flagBytes) > 0, nil
+}
+
+func readTokenAllowlist(ctx contractapi.TransactionContextInterface, tokenID string) ([]string, error) {
+	allowlistKey, err := ctx.GetStub().CreateCompositeKey(tokenAllowlistPrefix, []string{tokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", tokenAllowlistPrefix, err)
+	}
+	allowlistBytes, err := ctx.GetStub().GetState(allowlistKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transfer allowlist of token %s: %v", tokenID, err)
+	}
+
+	recipients := []string{}
+	if len(allowlistBytes) == 0 {
+		return recipients, nil
+	}
+
+	err = json.Unmarshal(allowlistBytes, &recipients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transfer allowlist of token %s: %v", tokenID, err)
+	}
+
+	return recipients, nil
+}
+
+func deleteTokenAllowlist(ctx contractapi.TransactionContextInterface, tokenID string) error {
+	allowlistKey, err := ctx.GetStub().CreateCompositeKey(tokenAllowlistPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", tokenAllowlistPrefix, err)
+	}
+	err = ctx.GetStub().DelState(allowlistKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete transfer allowlist of token %s: %v", tokenID, err)
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readIntOption reads an integer option, returning 0 if it was never set
+func readIntOption(ctx contractapi.TransactionContextInterface, key string) (int, error) {
+	valueBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %v", key, err)
+	}
+	if len(valueBytes) == 0 {
+		return 0, nil
+	}
+
+	value, _ := strconv.Atoi(string(valueBytes)) // Error handling not needed since Itoa() was used when setting the option, guaranteeing it was an integer.
+
+	return value, nil
+}
+
+// Transfer rules
+
+func checkAllowlistRule(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) error {
+	allowed, err := hasFlag(ctx, allowlistPrefix, to)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("recipient %s is not on the allowlist", to)
+	}
+
+	return nil
+}
+
+func checkBlocklistRule(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) error {
+	for _, account := range []string{from, to} {
+		blocked, err := hasFlag(ctx, blocklistPrefix, account)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return fmt.Errorf("account %s is blocklisted", account)
+		}
+	}
+
+	return nil
+}
+
+func checkProfileRule(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) error {
+	profile, err := readProfile(ctx, to)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return fmt.Errorf("recipient %s has not set an account profile", to)
+	}
+
+	return nil
+}
+
+func checkCooldownRule(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) error {
+	cooldown, err := readIntOption(ctx, transferCooldownKey)
+	if err != nil {
+		return err
+	}
+
+	lastTransferKey, err := ctx.GetStub().CreateCompositeKey(lastTransferPrefix, []string{tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", lastTransferPrefix, err)
+	}
+	lastTransferBytes, err := ctx.GetStub().GetState(lastTransferKey)
+	if err != nil {
+		return fmt.Errorf("failed to read last transfer time of token %s: %v", tokenID, err)
+	}
+
+	// A token that never moved since it was minted is not cooling down
+	if len(lastTransferBytes) == 0 {
+		return nil
+	}
+
+	lastTransfer, _ := strconv.ParseInt(string(lastTransferBytes), 10, 64) // Error handling not needed since FormatInt() was used when setting the time, guaranteeing it was an integer.
+
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	if now < lastTransfer+int64(cooldown) {
+		return fmt.Errorf("token %s is cooling down until %d", tokenID, lastTransfer+int64(cooldown))
+	}
+
+	return nil
+}
+
+func checkCapRule(ctx contractapi.TransactionContextInterface, from string, to string, tokenID string) error {
+	return checkBalanceCap(ctx, to, 1)
+}
+
+// checkBalanceCap rejects moving incoming tokens to an account that would then hold more than the balance cap
+// A transaction does not read its own writes, so a function moving several tokens to one account in a single
+// transaction must check their total, the cap rule only ever sees the balance before the transaction.
+func checkBalanceCap(ctx contractapi.TransactionContextInterface, to string, incoming int) error {
+	balanceCap, err := readIntOption(ctx, balanceCapKey)
+	if err != nil {
+		return err
+	}
+
+	// Without a configured cap there is nothing to enforce
+	if balanceCap == 0 {
+		return nil
+	}
+
+	balance, err := balanceOf(ctx, to)
+	if err != nil {
+		return err
+	}
+	if balance+incoming > balanceCap {
+		return fmt.Errorf("recipient %s would exceed the balance cap of %d", to, balanceCap)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableTransferRule(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+
+	rules, err := tokenContract.ListTransferRules(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.TransferRule{
+		{Name: "allowlist", Enabled: false},
+		{Name: "blocklist", Enabled: false},
+		{Name: "cooldown", Enabled: false},
+		{Name: "cap", Enabled: false},
+		{Name: "profile", Enabled: false},
+	}, rules)
+
+	err = tokenContract.EnableTransferRule(prepMocks(stub, org2MSP, recipient), "blocklist", true)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	err = tokenContract.EnableTransferRule(adminCtx, "unknown", true)
+	require.EqualError(t, err, "unknown transfer rule unknown")
+
+	err = tokenContract.EnableTransferRule(adminCtx, "blocklist", true)
+	require.NoError(t, err)
+	err = tokenContract.EnableTransferRule(adminCtx, "cap", true)
+	require.NoError(t, err)
+	err = tokenContract.EnableTransferRule(adminCtx, "cap", false)
+	require.NoError(t, err)
+
+	rules, err = tokenContract.ListTransferRules(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.TransferRule{
+		{Name: "allowlist", Enabled: false},
+		{Name: "blocklist", Enabled: true},
+		{Name: "cooldown", Enabled: false},
+		{Name: "cap", Enabled: false},
+		{Name: "profile", Enabled: false},
+	}, rules)
+}
+
+func TestTransferRulesEnforcement(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+
+	// A blocklisted recipient is accepted while the rule is disabled
+	require.NoError(t, tokenContract.SetBlocklisted(adminCtx, recipient, true))
+	_, err := tokenContract.TransferFrom(adminCtx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	require.NoError(t, tokenContract.EnableTransferRule(adminCtx, "blocklist", true))
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "102")
+	require.EqualError(t, err, "transfer rejected by rule blocklist: account recipient is blocklisted")
+
+	// The recipient is blocklisted and not allowlisted, the allowlist rule is enforced first
+	require.NoError(t, tokenContract.EnableTransferRule(adminCtx, "allowlist", true))
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "102")
+	require.EqualError(t, err, "transfer rejected by rule allowlist: recipient recipient is not on the allowlist")
+
+	require.NoError(t, tokenContract.SetAllowlisted(adminCtx, recipient, true))
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "102")
+	require.EqualError(t, err, "transfer rejected by rule blocklist: account recipient is blocklisted")
+
+	require.NoError(t, tokenContract.SetBlocklisted(adminCtx, recipient, false))
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "102")
+	require.NoError(t, err)
+}
+
+func TestTransferRuleCooldown(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+
+	require.NoError(t, tokenContract.SetTransferCooldown(adminCtx, 60))
+	require.NoError(t, tokenContract.EnableTransferRule(adminCtx, "cooldown", true))
+
+	setTxTime(stub, 1000)
+	_, err := tokenContract.TransferFrom(adminCtx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	setTxTime(stub, 1059)
+	_, err = tokenContract.TransferFrom(recipientCtx, recipient, minter, "101")
+	require.EqualError(t, err, "transfer rejected by rule cooldown: token 101 is cooling down until 1060")
+
+	setTxTime(stub, 1060)
+	_, err = tokenContract.TransferFrom(recipientCtx, recipient, minter, "101")
+	require.NoError(t, err)
+}
+
+func TestTransferRuleCap(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102", "103")
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetBalanceCap(adminCtx, 0)
+	require.EqualError(t, err, "balance cap must be a positive integer")
+
+	require.NoError(t, tokenContract.SetBalanceCap(adminCtx, 2))
+	require.NoError(t, tokenContract.EnableTransferRule(adminCtx, "cap", true))
+
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "101")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "102")
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "103")
+	require.EqualError(t, err, "transfer rejected by rule cap: recipient recipient would exceed the balance cap of 2")
+}
+
+func TestTokenTransferAllowlist(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101", "102")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetTokenTransferAllowlist(prepMocks(stub, org2MSP, recipient), "101", []string{recipient})
+	require.EqualError(t, err, "non-fungible token 101 is not owned by recipient")
+
+	err = tokenContract.SetTokenTransferAllowlist(minterCtx, "101", []string{recipient})
+	require.NoError(t, err)
+
+	recipients, err := tokenContract.GetTokenTransferAllowlist(minterCtx, "101")
+	require.NoError(t, err)
+	require.Equal(t, []string{recipient}, recipients)
+
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "101")
+	require.EqualError(t, err, "recipient operator is not on the transfer allowlist of token 101")
+
+	_, err = tokenContract.TransferFrom(minterCtx, minter, recipient, "101")
+	require.NoError(t, err)
+
+	// Tokens without a list are unrestricted
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "102")
+	require.NoError(t, err)
+
+	// The new owner can lift the restriction
+	recipientCtx := prepMocks(stub, org2MSP, recipient)
+	err = tokenContract.SetTokenTransferAllowlist(recipientCtx, "101", []string{})
+	require.NoError(t, err)
+	_, err = tokenContract.TransferFrom(recipientCtx, recipient, operator, "101")
+	require.NoError(t, err)
+}
+
+func TestTokenTransferAllowlistClearedOnBurn(t *testing.T) {
+	stub := newMockStub()
+	mintTokens(t, stub, "101")
+	tokenContract := chaincode.SmartContract{}
+	minterCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetTokenTransferAllowlist(minterCtx, "101", []string{recipient})
+	require.NoError(t, err)
+	_, err = tokenContract.Burn(minterCtx, "101")
+	require.NoError(t, err)
+
+	// A token minted later with the same ID does not inherit the list
+	mintTokens(t, stub, "101")
+	recipients, err := tokenContract.GetTokenTransferAllowlist(minterCtx, "101")
+	require.NoError(t, err)
+	require.Empty(t, recipients)
+	_, err = tokenContract.TransferFrom(minterCtx, minter, operator, "101")
+	require.NoError(t, err)
+}
+
+func TestSetTransfersEnabledAt(t *testing.T) {
+	stub := newMockStub()
+	tokenContract := chaincode.SmartContract{}
+	adminCtx := prepMocks(stub, org1MSP, minter)
+
+	err := tokenContract.SetTransfersEnabledAt(prepMocks(stub, org2MSP, recipient), 2000)
+	require.EqualError(t, err, "client is not authorized to perform admin functions")
+
+	err = tokenContract.SetTransfersEnabledAt(adminCtx, 2000)
+	require.NoError(t, err)
+
+	// Minting is open before the launch, transfers are not
+	setTxTime(stub, 1999)
+	mintTokens(t, stub, "101")
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "101")
+	require.EqualError(t, err, "transfers are disabled until 2000")
+
+	setTxTime(stub, 2000)
+	_, err = tokenContract.TransferFrom(adminCtx, minter, recipient, "101")
+	require.NoError(t, err)
+}
@@ -0,0 +1,174 @@
+// Package events defines the payloads of the events emitted by the token-erc-721 chaincode.
+// Off-chain Go clients can import it to decode the payload of a chaincode event received from a peer.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TransferEvent is the payload of a Transfer event
+// From is "0x0" for a mint and To is "0x0" for a burn.
+type TransferEvent struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	TokenID string `json:"tokenId"`
+}
+
+// ApprovalEvent is the payload of an Approval event
+type ApprovalEvent struct {
+	Owner    string `json:"owner"`
+	Approved string `json:"approved"`
+	TokenID  string `json:"tokenId"`
+}
+
+// ApprovalForAllEvent is the payload of an ApprovalForAll event
+type ApprovalForAllEvent struct {
+	Owner    string `json:"owner"`
+	Operator string `json:"operator"`
+	Approved bool   `json:"approved"`
+}
+
+// ApprovalForAllBatchEvent is the payload of an ApprovalForAllBatch event, emitted by SetApprovalForAllBatch
+// instead of one ApprovalForAll event per operator.
+type ApprovalForAllBatchEvent struct {
+	Owner     string   `json:"owner"`
+	Operators []string `json:"operators"`
+	Approved  bool     `json:"approved"`
+}
+
+// DecodeTransferEvent unmarshals the payload of a Transfer event
+func DecodeTransferEvent(payload []byte) (TransferEvent, error) {
+	var event TransferEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return TransferEvent{}, fmt.Errorf("failed to unmarshal Transfer event: %v", err)
+	}
+
+	return event, nil
+}
+
+// DecodeApprovalEvent unmarshals the payload of an Approval event
+func DecodeApprovalEvent(payload []byte) (ApprovalEvent, error) {
+	var event ApprovalEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return ApprovalEvent{}, fmt.Errorf("failed to unmarshal Approval event: %v", err)
+	}
+
+	return event, nil
+}
+
+// DecodeApprovalForAllEvent unmarshals the payload of an ApprovalForAll event
+func DecodeApprovalForAllEvent(payload []byte) (ApprovalForAllEvent, error) {
+	var event ApprovalForAllEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return ApprovalForAllEvent{}, fmt.Errorf("failed to unmarshal ApprovalForAll event: %v", err)
+	}
+
+	return event, nil
+}
+
+// DecodeApprovalForAllBatchEvent unmarshals the payload of an ApprovalForAllBatch event
+func DecodeApprovalForAllBatchEvent(payload []byte) (ApprovalForAllBatchEvent, error) {
+	var event ApprovalForAllBatchEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return ApprovalForAllBatchEvent{}, fmt.Errorf("failed to unmarshal ApprovalForAllBatch event: %v", err)
+	}
+
+	return event, nil
+}
+
+// SaleEvent is the payload of a Sale event, emitted instead of a Transfer event when a transfer is a sale
+type SaleEvent struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	TokenID   string `json:"tokenId"`
+	SalePrice int    `json:"salePrice"`
+}
+
+// DecodeSaleEvent unmarshals the payload of a Sale event
+func DecodeSaleEvent(payload []byte) (SaleEvent, error) {
+	var event SaleEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return SaleEvent{}, fmt.Errorf("failed to unmarshal Sale event: %v", err)
+	}
+
+	return event, nil
+}
+
+// MetadataUpdateEvent is the payload of a MetadataUpdate event, listing the tokens whose URI changed
+// RevealMetadata emits a single event for all revealed tokens.
+type MetadataUpdateEvent struct {
+	TokenIDs []string `json:"tokenIds"`
+}
+
+// DecodeMetadataUpdateEvent unmarshals the payload of a MetadataUpdate event
+func DecodeMetadataUpdateEvent(payload []byte) (MetadataUpdateEvent, error) {
+	var event MetadataUpdateEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return MetadataUpdateEvent{}, fmt.Errorf("failed to unmarshal MetadataUpdate event: %v", err)
+	}
+
+	return event, nil
+}
+
+// ReissuedEvent is the payload of a Reissued event, emitted when a token is migrated to a new URI in place
+type ReissuedEvent struct {
+	TokenID     string `json:"tokenId"`
+	PreviousURI string `json:"previousURI"`
+	TokenURI    string `json:"tokenURI"`
+}
+
+// DecodeReissuedEvent unmarshals the payload of a Reissued event
+func DecodeReissuedEvent(payload []byte) (ReissuedEvent, error) {
+	var event ReissuedEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return ReissuedEvent{}, fmt.Errorf("failed to unmarshal Reissued event: %v", err)
+	}
+
+	return event, nil
+}
+
+// AirdropEvent is the payload of an Airdrop event
+// TokenIDs[i] was transferred from From to Recipients[i].
+type AirdropEvent struct {
+	From       string   `json:"from"`
+	Recipients []string `json:"recipients"`
+	TokenIDs   []string `json:"tokenIds"`
+}
+
+// DecodeAirdropEvent unmarshals the payload of an Airdrop event
+func DecodeAirdropEvent(payload []byte) (AirdropEvent, error) {
+	var event AirdropEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return AirdropEvent{}, fmt.Errorf("failed to unmarshal Airdrop event: %v", err)
+	}
+
+	return event, nil
+}
+
+// RecoveryEvent is the payload of the RecoveryInitiated, RecoveryCancelled and Recovery events
+// TokenIDs lists the recovered tokens, and is only set for a Recovery event.
+type RecoveryEvent struct {
+	Owner    string   `json:"owner"`
+	Recovery string   `json:"recovery"`
+	TokenIDs []string `json:"tokenIds,omitempty"`
+}
+
+// DecodeRecoveryEvent unmarshals the payload of a RecoveryInitiated, RecoveryCancelled or Recovery event
+func DecodeRecoveryEvent(payload []byte) (RecoveryEvent, error) {
+	var event RecoveryEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return RecoveryEvent{}, fmt.Errorf("failed to unmarshal Recovery event: %v", err)
+	}
+
+	return event, nil
+}
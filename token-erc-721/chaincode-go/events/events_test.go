@@ -0,0 +1,117 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-721/chaincode-go/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTransferEvent(t *testing.T) {
+	payload, err := json.Marshal(events.TransferEvent{From: "0x0", To: "minter", TokenID: "101"})
+	require.NoError(t, err)
+
+	event, err := events.DecodeTransferEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, events.TransferEvent{From: "0x0", To: "minter", TokenID: "101"}, event)
+
+	_, err = events.DecodeTransferEvent([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestDecodeApprovalEvent(t *testing.T) {
+	payload, err := json.Marshal(events.ApprovalEvent{Owner: "minter", Approved: "operator", TokenID: "101"})
+	require.NoError(t, err)
+
+	event, err := events.DecodeApprovalEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, events.ApprovalEvent{Owner: "minter", Approved: "operator", TokenID: "101"}, event)
+
+	_, err = events.DecodeApprovalEvent([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestDecodeApprovalForAllEvent(t *testing.T) {
+	payload, err := json.Marshal(events.ApprovalForAllEvent{Owner: "minter", Operator: "operator", Approved: true})
+	require.NoError(t, err)
+
+	event, err := events.DecodeApprovalForAllEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, events.ApprovalForAllEvent{Owner: "minter", Operator: "operator", Approved: true}, event)
+
+	_, err = events.DecodeApprovalForAllEvent([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestDecodeApprovalForAllBatchEvent(t *testing.T) {
+	payload, err := json.Marshal(events.ApprovalForAllBatchEvent{Owner: "minter", Operators: []string{"market1", "market2"}, Approved: true})
+	require.NoError(t, err)
+
+	event, err := events.DecodeApprovalForAllBatchEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, events.ApprovalForAllBatchEvent{Owner: "minter", Operators: []string{"market1", "market2"}, Approved: true}, event)
+
+	_, err = events.DecodeApprovalForAllBatchEvent([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestDecodeSaleEvent(t *testing.T) {
+	payload, err := json.Marshal(events.SaleEvent{From: "minter", To: "recipient", TokenID: "101", SalePrice: 250})
+	require.NoError(t, err)
+
+	event, err := events.DecodeSaleEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, events.SaleEvent{From: "minter", To: "recipient", TokenID: "101", SalePrice: 250}, event)
+
+	_, err = events.DecodeSaleEvent([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestDecodeMetadataUpdateEvent(t *testing.T) {
+	payload, err := json.Marshal(events.MetadataUpdateEvent{TokenIDs: []string{"101", "102"}})
+	require.NoError(t, err)
+
+	event, err := events.DecodeMetadataUpdateEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, events.MetadataUpdateEvent{TokenIDs: []string{"101", "102"}}, event)
+
+	_, err = events.DecodeMetadataUpdateEvent([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestDecodeReissuedEvent(t *testing.T) {
+	payload, err := json.Marshal(events.ReissuedEvent{TokenID: "101", PreviousURI: "https://example.com/nft/101", TokenURI: "ipfs://101"})
+	require.NoError(t, err)
+
+	event, err := events.DecodeReissuedEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, events.ReissuedEvent{TokenID: "101", PreviousURI: "https://example.com/nft/101", TokenURI: "ipfs://101"}, event)
+
+	_, err = events.DecodeReissuedEvent([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestDecodeAirdropEvent(t *testing.T) {
+	payload, err := json.Marshal(events.AirdropEvent{From: "minter", Recipients: []string{"alice", "bob"}, TokenIDs: []string{"101", "102"}})
+	require.NoError(t, err)
+
+	event, err := events.DecodeAirdropEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, events.AirdropEvent{From: "minter", Recipients: []string{"alice", "bob"}, TokenIDs: []string{"101", "102"}}, event)
+
+	_, err = events.DecodeAirdropEvent([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestDecodeRecoveryEvent(t *testing.T) {
+	payload, err := json.Marshal(events.RecoveryEvent{Owner: "minter", Recovery: "recovery", TokenIDs: []string{"101"}})
+	require.NoError(t, err)
+
+	event, err := events.DecodeRecoveryEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, events.RecoveryEvent{Owner: "minter", Recovery: "recovery", TokenIDs: []string{"101"}}, event)
+
+	_, err = events.DecodeRecoveryEvent([]byte("not json"))
+	require.Error(t, err)
+}